@@ -3,14 +3,15 @@ module github.com/lugamuga/mattermost-yandex-calendar-plugin
 go 1.12
 
 require (
-	github.com/blang/semver/v4 v4.0.0 // indirect
+	github.com/blang/semver/v4 v4.0.0
 	github.com/emersion/go-ical v0.0.0-20200224201310-cd514449c39e
 	github.com/gorilla/mux v1.8.0
 	github.com/lugamuga/go-webdav v0.1.2
 	github.com/mattermost/mattermost-plugin-api v0.0.27
-	github.com/mattermost/mattermost-server/v6 v6.5.0 // indirect
+	github.com/mattermost/mattermost-server/v6 v6.5.0
 	github.com/mholt/archiver/v3 v3.5.1
 	github.com/pkg/errors v0.9.1
 	github.com/robfig/cron/v3 v3.0.1
+	github.com/teambition/rrule-go v1.8.2
 	github.com/tkuchiki/go-timezone v0.2.2
 )
@@ -2,7 +2,7 @@ package conf
 
 import (
 	"fmt"
-	"strconv"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
 	"strings"
 	"time"
 )
@@ -16,8 +16,12 @@ func GetIconUrl(siteUrl string, manifestId string) string {
 }
 
 const (
-	ApiV1Prefix      = "/api/v1"
-	CalendarSettings = "/calendar/settings"
+	ApiV1Prefix          = "/api/v1"
+	CalendarSettings     = "/calendar/settings"
+	CalendarCreateEvent  = "/calendar/event/create"
+	CalendarRespondEvent = "/calendar/event/respond"
+	CalendarCreateSlot   = "/calendar/event/slot"
+	CalendarCompleteTask = "/calendar/task/complete"
 )
 
 func ResolveUrlByPlugin(manifestId string, path string) string {
@@ -25,36 +29,327 @@ func ResolveUrlByPlugin(manifestId string, path string) string {
 }
 
 const (
-	SelectCalendarDialogOption     = "calendar"
-	SelectTimezoneDialogOption     = "timezone"
-	DailyNotifyTimeDialogOption    = "dailyNotifyTime"
-	TenMinuteNotifyDialogOption    = "tenMinutesNotify"
-	OneMinuteNotifyDialogOption    = "oneMinuteNotify"
-	ChangeStatusOnMeetDialogOption = "changeStatusOnMeet"
+	SelectCalendarDialogOption        = "calendar"
+	SelectTimezoneDialogOption        = "timezone"
+	DailyNotifyTimeDialogOption       = "dailyNotifyTime"
+	ReminderOffsetsDialogOption       = "reminderOffsets"
+	ChangeStatusOnMeetDialogOption    = "changeStatusOnMeet"
+	ShareEventTitlesDialogOption      = "shareEventTitles"
+	EventAlarmsDialogOption           = "eventAlarms"
+	ThreadedNotificationsDialogOption = "threadedNotifications"
+	WorkingHoursDialogOption          = "workingHours"
+)
+
+const (
+	EventCalendarDialogOption    = "calendar"
+	EventTitleDialogOption       = "title"
+	EventDateDialogOption        = "date"
+	EventStartDialogOption       = "start"
+	EventDurationDialogOption    = "duration"
+	EventDescriptionDialogOption = "description"
+	EventLocationDialogOption    = "location"
+	EventAttendeesDialogOption   = "attendees"
+)
+
+const (
+	EventObjectPathActionContext   = "objectPath"
+	EventRecurrenceIdActionContext = "recurrenceId"
+	EventOccurrenceIdActionContext = "occurrenceId"
+	EventPartStatActionContext     = "partStat"
+	SlotStartActionContext         = "start"
+	SlotEndActionContext           = "end"
+	SlotParticipantsActionContext  = "participants"
+	TaskIdActionContext            = "taskId"
+)
+
+// Props of posts with events, they are used to find and update events in posts
+const (
+	EventOccurrenceIdsPostProp = "eventOccurrenceIds"
+	EventTemplatePostProp      = "eventTemplate"
+)
+
+const (
+	// AgendaMaxDays limits range of agenda, as all recurring events are expanded for it
+	AgendaMaxDays            = 31
+	AgendaPostMaxAttachments = 50
+	// AgendaPostMaxSize is less than Mattermost post limit of 16383 runes
+	AgendaPostMaxSize = 12000
+)
+
+const (
+	// Events are cached from yesterday to the next week, so reminders after midnight aren't missed
+	EventCachePastDays   = 1
+	EventCacheFutureDays = 7
+	// EventCacheMaxAgeMinutes is more than interval of updates, older cache isn't used for summaries
+	EventCacheMaxAgeMinutes = 15
+	// MovedEventSearchDays limits search of event which was moved out of cache window
+	MovedEventSearchDays = 365
+)
+
+const (
+	SearchDefaultPastDays   = 30
+	SearchDefaultFutureDays = 90
+	// SearchMaxDays limits range of search, as all events of range are loaded from server
+	SearchMaxDays = 366
+)
+
+const (
+	FindTimeSlotsLimit     = 5
+	FindTimeDefaultDays    = 7
+	FindTimeDefaultMinutes = 30
 )
 
 const (
 	DailyNotifyTimeDisableOption = "Never"
+	// DialogDateFormat is dd.MM.yyyy
+	DialogDateFormat = "02.01.2006"
+)
+
+// GetCalendarDialogOption builds name of dialog option for calendar path
+func GetCalendarDialogOption(calendarPath string) string {
+	return SelectCalendarDialogOption + ":" + calendarPath
+}
+
+// ParseCalendarDialogOption returns calendar path if dialog option is calendar option
+func ParseCalendarDialogOption(option string) (string, bool) {
+	prefix := SelectCalendarDialogOption + ":"
+	if !strings.HasPrefix(option, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(option, prefix), true
+}
+
+const (
+	MsgYesterdayEventsTitle MessageId = "yesterdayEventsTitle"
+	MsgTomorrowEventsTitle  MessageId = "tomorrowEventsTitle"
+	MsgTodayEventsTitle     MessageId = "todayEventsTitle"
+	MsgAddedEventsTitle     MessageId = "addedEventsTitle"
+	MsgUpdatedEventsTitle   MessageId = "updatedEventsTitle"
+	MsgRemovedEventsTitle   MessageId = "removedEventsTitle"
+	MsgCreatedEventTitle    MessageId = "createdEventTitle"
+	MsgBusyEventsTitle      MessageId = "busyEventsTitle"
+	MsgFreeSlotsTitle       MessageId = "freeSlotsTitle"
+	MsgWeekEventsTitle      MessageId = "weekEventsTitle"
+	MsgNextWeekEventsTitle  MessageId = "nextWeekEventsTitle"
+	MsgAgendaEventsTitle    MessageId = "agendaEventsTitle"
+	MsgReminderEventTitle   MessageId = "reminderEventTitle"
+	MsgReminderStartedTitle MessageId = "reminderStartedTitle"
+	MsgAlarmEventTitle      MessageId = "alarmEventTitle"
+	MsgAlarmStartedTitle    MessageId = "alarmStartedTitle"
+	MsgAlarmEndTitle        MessageId = "alarmEndTitle"
+	MsgAlarmEndedTitle      MessageId = "alarmEndedTitle"
+	MsgSearchEventsTitle    MessageId = "searchEventsTitle"
+	MsgTasksTitle           MessageId = "tasksTitle"
+	MsgTodayTasksTitle      MessageId = "todayTasksTitle"
+	MsgTaskDueTitle         MessageId = "taskDueTitle"
+)
+
+const (
+	MsgFreeDayMessage              MessageId = "freeDayMessage"
+	MsgNoFreeSlotsMessage          MessageId = "noFreeSlotsMessage"
+	MsgSlotEventName               MessageId = "slotEventName"
+	MsgBusyIntervalName            MessageId = "busyIntervalName"
+	MsgNoTasksMessage              MessageId = "noTasksMessage"
+	MsgColleagueNotConnected       MessageId = "colleagueNotConnected"
+	MsgNotConnectedMessage         MessageId = "notConnectedMessage"
+	MsgDateFormatsHelp             MessageId = "dateFormatsHelp"
+	MsgWelcomeMessage              MessageId = "welcomeMessage"
+	MsgWrongCommandMessage         MessageId = "wrongCommandMessage"
+	MsgByeMessage                  MessageId = "byeMessage"
+	MsgNoEventsMessage             MessageId = "noEventsMessage"
+	MsgLoadEventsErrorMessage      MessageId = "loadEventsErrorMessage"
+	MsgLoadTasksErrorMessage       MessageId = "loadTasksErrorMessage"
+	MsgWrongRangeMessage           MessageId = util.WrongRangeMessage
+	MsgSearchQueryRequiredMessage  MessageId = "searchQueryRequiredMessage"
+	MsgColleagueRequiredMessage    MessageId = "colleagueRequiredMessage"
+	MsgParticipantsRequiredMessage MessageId = "participantsRequiredMessage"
+	MsgUserNotFoundMessage         MessageId = "userNotFoundMessage"
+	MsgChannelNotFoundMessage      MessageId = "channelNotFoundMessage"
+	MsgNotChannelMemberMessage     MessageId = "notChannelMemberMessage"
+	MsgChannelMembersErrorMessage  MessageId = "channelMembersErrorMessage"
+	MsgNoCalendarSelectedMessage   MessageId = "noCalendarSelectedMessage"
+	MsgWrongDialogDateMessage      MessageId = "wrongDialogDateMessage"
+	MsgStartRequiredMessage        MessageId = "startRequiredMessage"
+	MsgDurationRequiredMessage     MessageId = "durationRequiredMessage"
+	MsgCalendarRequiredMessage     MessageId = "calendarRequiredMessage"
+	MsgWrongEmailMessage           MessageId = "wrongEmailMessage"
+	MsgInMeetingStatus             MessageId = "inMeetingStatus"
+)
+
+// Messages of channel subscriptions
+const (
+	MsgWrongChannelCommandMessage    MessageId = "wrongChannelCommandMessage"
+	MsgDirectChannelMessage          MessageId = "directChannelMessage"
+	MsgNotChannelAdminMessage        MessageId = "notChannelAdminMessage"
+	MsgChannelCalendarNotFound       MessageId = "channelCalendarNotFound"
+	MsgChannelCalendarSubscribed     MessageId = "channelCalendarSubscribed"
+	MsgChannelCalendarNotSubscribed  MessageId = "channelCalendarNotSubscribed"
+	MsgSubscribeChannelErrorMessage  MessageId = "subscribeChannelErrorMessage"
+	MsgChannelSubscribedMessage      MessageId = "channelSubscribedMessage"
+	MsgChannelUnsubscribedMessage    MessageId = "channelUnsubscribedMessage"
+	MsgChannelSubscriptionsTitle     MessageId = "channelSubscriptionsTitle"
+	MsgNoChannelSubscriptionsMessage MessageId = "noChannelSubscriptionsMessage"
 )
 
+// Errors of calendar are shown to users, their messages are translated
 const (
-	YesterdayEventsTitle = "##### :calendar: Yesterday"
-	TomorrowEventsTitle  = "##### :calendar: Tomorrow"
-	TodayEventsTitle     = "##### :calendar: Today"
-	AddedEventsTitle     = "##### :new: Added events"
-	UpdatedEventsTitle   = "##### :arrows_counterclockwise: Updated events"
-	TenMinutesEventTitle = "##### :clock10: 10 minutes until event"
-	OneMinuteEventTitle  = "##### :alarm_clock: 1 minute until event"
+	MsgCalendarClientError       MessageId = "calendarClientError"
+	MsgGetEventsError            MessageId = "getEventsError"
+	MsgGetEventError             MessageId = "getEventError"
+	MsgCreateEventError          MessageId = "createEventError"
+	MsgSaveEventError            MessageId = "saveEventError"
+	MsgEventChangedError         MessageId = "eventChangedError"
+	MsgGetTasksError             MessageId = "getTasksError"
+	MsgGetTaskError              MessageId = "getTaskError"
+	MsgSaveTaskError             MessageId = "saveTaskError"
+	MsgTaskChangedError          MessageId = "taskChangedError"
+	MsgCalendarNotConnectedError MessageId = "calendarNotConnectedError"
+	MsgEventNotFoundError        MessageId = "eventNotFoundError"
+	MsgNotAttendeeError          MessageId = "notAttendeeError"
+	MsgCalendarHomeSetError      MessageId = "calendarHomeSetError"
 )
 
-func GetTodayEventsTitle(dt time.Time) string {
-	return GetEventsTitle(TodayEventsTitle, dt)
+const (
+	MsgSettingsDialogTitle             MessageId = "settingsDialogTitle"
+	MsgSettingsDialogSubmit            MessageId = "settingsDialogSubmit"
+	MsgCalendarDialogElement           MessageId = "calendarDialogElement"
+	MsgTimezoneDialogElement           MessageId = "timezoneDialogElement"
+	MsgDailyNotifyTimeDialogElement    MessageId = "dailyNotifyTimeDialogElement"
+	MsgDailyNotifyTimeDisableOption    MessageId = "dailyNotifyTimeDisableOption"
+	MsgChangeStatusOnMeetDialogElement MessageId = "changeStatusOnMeetDialogElement"
+	MsgReminderOffsetsDialogElement    MessageId = "reminderOffsetsDialogElement"
+	MsgReminderOffsetsDialogHelp       MessageId = "reminderOffsetsDialogHelp"
+	MsgEventAlarmsDialogElement        MessageId = "eventAlarmsDialogElement"
+	MsgEventAlarmsOffOption            MessageId = "eventAlarmsOffOption"
+	MsgEventAlarmsInsteadOption        MessageId = "eventAlarmsInsteadOption"
+	MsgEventAlarmsAdditionalOption     MessageId = "eventAlarmsAdditionalOption"
+	MsgThreadedDialogElement           MessageId = "threadedDialogElement"
+	MsgShareEventTitlesDialogElement   MessageId = "shareEventTitlesDialogElement"
+	MsgWorkingHoursDialogElement       MessageId = "workingHoursDialogElement"
+	MsgWorkingHoursDialogHelp          MessageId = "workingHoursDialogHelp"
+	MsgCreateEventDialogTitle          MessageId = "createEventDialogTitle"
+	MsgCreateEventDialogSubmit         MessageId = "createEventDialogSubmit"
+	MsgTitleDialogElement              MessageId = "titleDialogElement"
+	MsgEventCalendarDialogElement      MessageId = "eventCalendarDialogElement"
+	MsgDateDialogElement               MessageId = "dateDialogElement"
+	MsgDateDialogHelp                  MessageId = "dateDialogHelp"
+	MsgStartDialogElement              MessageId = "startDialogElement"
+	MsgDurationDialogElement           MessageId = "durationDialogElement"
+	MsgDescriptionDialogElement        MessageId = "descriptionDialogElement"
+	MsgLocationDialogElement           MessageId = "locationDialogElement"
+	MsgAttendeesDialogElement          MessageId = "attendeesDialogElement"
+	MsgAttendeesDialogHelp             MessageId = "attendeesDialogHelp"
+)
+
+const (
+	MsgTentativeEventMark   MessageId = "tentativeEventMark"
+	MsgNeedsActionEventMark MessageId = "needsActionEventMark"
+	MsgOverdueTaskMark      MessageId = "overdueTaskMark"
+	MsgCancelledEventMark   MessageId = "cancelledEventMark"
+)
+
+const (
+	MsgAcceptEventAction    MessageId = "acceptEventAction"
+	MsgTentativeEventAction MessageId = "tentativeEventAction"
+	MsgDeclineEventAction   MessageId = "declineEventAction"
+	MsgEventResponseField   MessageId = "eventResponseField"
+	MsgCreateSlotAction     MessageId = "createSlotAction"
+	MsgSlotCreatedField     MessageId = "slotCreatedField"
+	MsgSlotCreatedValue     MessageId = "slotCreatedValue"
+	MsgCompleteTaskAction   MessageId = "completeTaskAction"
+	MsgTaskStatusField      MessageId = "taskStatusField"
+	MsgTaskCompletedValue   MessageId = "taskCompletedValue"
+)
+
+const (
+	MsgAcceptedEventResponse  MessageId = "acceptedEventResponse"
+	MsgTentativeEventResponse MessageId = "tentativeEventResponse"
+	MsgDeclinedEventResponse  MessageId = "declinedEventResponse"
+)
+
+const (
+	LocationEventMark               = ":round_pushpin:"
+	ConferenceEventMark             = ":movie_camera:"
+	MsgConferenceLinkName MessageId = "conferenceLinkName"
+	MsgJoinConferenceLink MessageId = "joinConferenceLink"
+)
+
+const (
+	MsgAllDayEventsSubtitle MessageId = "allDayEventsSubtitle"
+	MsgTimedEventsSubtitle  MessageId = "timedEventsSubtitle"
+)
+
+func GetReminderTitle(language string, offsetMinutes int) string {
+	if offsetMinutes <= 0 {
+		return T(language, MsgReminderStartedTitle)
+	}
+	return Tf(language, MsgReminderEventTitle, util.FormatLocalizedDuration(time.Duration(offsetMinutes)*time.Minute, language))
 }
 
-func GetEventsTitle(name string, dt time.Time) string {
-	part := name + " - " + dt.Weekday().String()
+// GetAlarmTitle shows time until start or end of event which alarm is related to
+func GetAlarmTitle(language string, untilAnchor time.Duration, relatedEnd bool) string {
+	if relatedEnd && untilAnchor <= 0 {
+		return T(language, MsgAlarmEndedTitle)
+	}
+	if relatedEnd {
+		return Tf(language, MsgAlarmEndTitle, util.FormatLocalizedDuration(untilAnchor, language))
+	}
+	if untilAnchor <= 0 {
+		return T(language, MsgAlarmStartedTitle)
+	}
+	return Tf(language, MsgAlarmEventTitle, util.FormatLocalizedDuration(untilAnchor, language))
+}
+
+// GetRangeEventsTitle adds dates range to translated name of title
+func GetRangeEventsTitle(language string, name string, start time.Time, end time.Time) string {
+	return fmt.Sprintf("%s: %s - %s", name, util.FormatDate(start, language), util.FormatDate(end, language))
+}
+
+func GetTodayEventsTitle(language string, dt time.Time) string {
+	return GetEventsTitle(language, T(language, MsgTodayEventsTitle), dt)
+}
+
+// GetEventsTitle adds weekday and date to translated name of title, only date is shown for empty name
+func GetEventsTitle(language string, name string, dt time.Time) string {
+	weekday := util.GetWeekdayName(dt.Weekday(), language)
+	part := name + " - " + weekday
 	if name == "" {
-		part = "##### :calendar: " + dt.Weekday().String()
+		part = "##### :calendar: " + weekday
 	}
-	return fmt.Sprintf("%s, %s %s", part, dt.Month(), strconv.Itoa(dt.Day()))
+	return fmt.Sprintf("%s, %s", part, util.FormatDate(dt, language))
 }
+
+// MsgCommandHelp - about
+const MsgCommandHelp MessageId = "commandHelp"
+
+const (
+	MsgCommandDescription           MessageId = "commandDescription"
+	MsgConnectCommandDescription    MessageId = "connectCommandDescription"
+	MsgDisconnectCommandDescription MessageId = "disconnectCommandDescription"
+	MsgUpdateCommandDescription     MessageId = "updateCommandDescription"
+	MsgSettingsCommandDescription   MessageId = "settingsCommandDescription"
+	MsgSummaryCommandDescription    MessageId = "summaryCommandDescription"
+	MsgSummaryCommandArgument       MessageId = "summaryCommandArgument"
+	MsgWeekCommandDescription       MessageId = "weekCommandDescription"
+	MsgWeekCommandArgument          MessageId = "weekCommandArgument"
+	MsgNextWeekCommandItem          MessageId = "nextWeekCommandItem"
+	MsgAgendaCommandDescription     MessageId = "agendaCommandDescription"
+	MsgAgendaCommandArgument        MessageId = "agendaCommandArgument"
+	MsgSearchCommandDescription     MessageId = "searchCommandDescription"
+	MsgSearchCommandArgument        MessageId = "searchCommandArgument"
+	MsgTasksCommandDescription      MessageId = "tasksCommandDescription"
+	MsgCreateCommandDescription     MessageId = "createCommandDescription"
+	MsgBusyCommandDescription       MessageId = "busyCommandDescription"
+	MsgBusyCommandArgument          MessageId = "busyCommandArgument"
+	MsgFindTimeCommandDescription   MessageId = "findTimeCommandDescription"
+	MsgFindTimeCommandArgument      MessageId = "findTimeCommandArgument"
+	MsgHelpCommandDescription       MessageId = "helpCommandDescription"
+)
+
+const (
+	MsgChannelCommandDescription     MessageId = "channelCommandDescription"
+	MsgSubscribeCommandDescription   MessageId = "subscribeCommandDescription"
+	MsgUnsubscribeCommandDescription MessageId = "unsubscribeCommandDescription"
+	MsgListChannelCommandDescription MessageId = "listChannelCommandDescription"
+	MsgChannelCalendarArgument       MessageId = "channelCalendarArgument"
+)
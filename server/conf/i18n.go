@@ -0,0 +1,44 @@
+package conf
+
+import (
+	"fmt"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+)
+
+// MessageId identifies message in bundles of languages
+type MessageId string
+
+// translations keep bundles of messages by language, English bundle is used for missing translations
+var translations = map[string]map[MessageId]string{
+	util.LanguageEn: enMessages,
+	util.LanguageRu: ruMessages,
+}
+
+// T translates message into language, id is returned as is if message is unknown
+func T(language string, id MessageId) string {
+	if translated, ok := translations[language][id]; ok {
+		return translated
+	}
+	if message, ok := enMessages[id]; ok {
+		return message
+	}
+	return string(id)
+}
+
+// Tf translates format into language and fills it with args
+func Tf(language string, format MessageId, args ...interface{}) string {
+	return fmt.Sprintf(T(language, format), args...)
+}
+
+// Error is shown to users, its message is translated into language of user
+type Error struct {
+	Id MessageId
+}
+
+func NewError(id MessageId) error {
+	return &Error{Id: id}
+}
+
+func (e *Error) Error() string {
+	return T(util.LanguageEn, e.Id)
+}
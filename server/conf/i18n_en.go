@@ -0,0 +1,199 @@
+package conf
+
+import "github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+
+// enMessages is the default bundle, it's used for languages and messages without translation
+var enMessages = map[MessageId]string{
+	MsgYesterdayEventsTitle: "##### :calendar: Yesterday",
+	MsgTomorrowEventsTitle:  "##### :calendar: Tomorrow",
+	MsgTodayEventsTitle:     "##### :calendar: Today",
+	MsgAddedEventsTitle:     "##### :new: Added events",
+	MsgUpdatedEventsTitle:   "##### :arrows_counterclockwise: Updated events",
+	MsgRemovedEventsTitle:   "##### :x: Removed/Cancelled events",
+	MsgCreatedEventTitle:    "##### :white_check_mark: Event created",
+	MsgBusyEventsTitle:      "##### :no_entry: Busy @",
+	MsgFreeSlotsTitle:       "##### :handshake: Common free time",
+	MsgWeekEventsTitle:      "#### :spiral_calendar_pad: Week",
+	MsgNextWeekEventsTitle:  "#### :spiral_calendar_pad: Next week",
+	MsgAgendaEventsTitle:    "#### :spiral_calendar_pad: Agenda",
+	MsgReminderEventTitle:   "##### :alarm_clock: %s until event",
+	MsgReminderStartedTitle: "##### :alarm_clock: Event starts now",
+	MsgAlarmEventTitle:      "##### :bell: %s until event",
+	MsgAlarmStartedTitle:    "##### :bell: Event started",
+	MsgAlarmEndTitle:        "##### :bell: %s until end of event",
+	MsgAlarmEndedTitle:      "##### :bell: Event ended",
+	MsgSearchEventsTitle:    "#### :mag: Search \"%s\"",
+	MsgTasksTitle:           "#### :ballot_box_with_check: Tasks",
+	MsgTodayTasksTitle:      "##### :ballot_box_with_check: Tasks for today",
+	MsgTaskDueTitle:         "##### :hourglass: Task is due now",
+
+	MsgFreeDayMessage:        ":palm_tree: No busy time",
+	MsgNoFreeSlotsMessage:    "No common free time in working hours",
+	MsgSlotEventName:         "Meeting",
+	MsgBusyIntervalName:      "Busy",
+	MsgNoTasksMessage:        "No open tasks with due date",
+	MsgColleagueNotConnected: "@%s hasn't connected Yandex Calendar",
+	MsgNotConnectedMessage:   "Please connect your calendar with **/calendar connect [login] [token]** and select calendars in **/calendar settings**",
+	MsgDateFormatsHelp: "Please use dd.MM.yyyy, dd.MM, yyyy-MM-dd, today, tomorrow, yesterday, weekday like friday or next friday, " +
+		"offset like +2 or in 3 days. Russian words work too: завтра, пятница, следующий понедельник, через 3 дня",
+	MsgWelcomeMessage: "#### Welcome to the Mattermost Yandex Calendar Plugin!\n" +
+		"Please type **/calendar help** to understand how to use this plugin. ",
+	MsgWrongCommandMessage: "Wrong command format. Please read " +
+		"**[instruction](https://github.com/LugaMuga/mattermost-yandex-calendar-plugin/blob/master/docs/readme.md)**",
+	MsgByeMessage:                  "Bye, bye :wave:",
+	MsgNoEventsMessage:             "No events",
+	MsgLoadEventsErrorMessage:      ":no_entry_sign: Catch error on load events",
+	MsgLoadTasksErrorMessage:       ":no_entry_sign: Catch error on load tasks",
+	MsgWrongRangeMessage:           "End of range should be after its start and not too far from it",
+	MsgSearchQueryRequiredMessage:  "Please specify text to find: **/calendar search [query] [from] [to]**",
+	MsgColleagueRequiredMessage:    "Please specify colleague: **/calendar busy @user [date]**",
+	MsgParticipantsRequiredMessage: "Please specify connected colleagues: **/calendar findtime @user ~channel [duration] [from] [to]**",
+	MsgUserNotFoundMessage:         "Can't find user @%s",
+	MsgChannelNotFoundMessage:      "Can't find channel ~%s",
+	MsgNotChannelMemberMessage:     "You aren't member of channel ~%s",
+	MsgChannelMembersErrorMessage:  "Can't get members of channel ~%s",
+	MsgNoCalendarSelectedMessage:   "Please select at least one calendar",
+	MsgWrongDialogDateMessage:      "Please use format dd.MM.yyyy or words like tomorrow, friday",
+	MsgStartRequiredMessage:        "Please select start time",
+	MsgDurationRequiredMessage:     "Please select duration",
+	MsgCalendarRequiredMessage:     "Please select calendar",
+	MsgWrongEmailMessage:           "Wrong email: %s",
+	MsgInMeetingStatus:             "In meeting",
+
+	MsgWrongChannelCommandMessage:    "Please use **/calendar channel subscribe [calendar]**, **/calendar channel unsubscribe [calendar]** or **/calendar channel list**",
+	MsgDirectChannelMessage:          "Calendars can be subscribed only in public and private channels",
+	MsgNotChannelAdminMessage:        "Only channel admins can change calendars of channel",
+	MsgChannelCalendarNotFound:       "Can't find calendar **%s**, your calendars: %s",
+	MsgChannelCalendarSubscribed:     "Calendar **%s** is already subscribed in this channel",
+	MsgChannelCalendarNotSubscribed:  "Calendar **%s** isn't subscribed in this channel",
+	MsgSubscribeChannelErrorMessage:  ":no_entry_sign: Can't subscribe channel to calendar",
+	MsgChannelSubscribedMessage:      ":spiral_calendar_pad: @%s subscribed channel to calendar **%s**. Daily schedule, event updates and reminders at start of events will be posted here",
+	MsgChannelUnsubscribedMessage:    ":spiral_calendar_pad: @%s unsubscribed channel from calendar **%s**",
+	MsgChannelSubscriptionsTitle:     "##### :spiral_calendar_pad: Calendars of channel",
+	MsgNoChannelSubscriptionsMessage: "Channel has no subscribed calendars",
+
+	MsgCalendarClientError:       "Can't get client for calendar",
+	MsgGetEventsError:            "Can't get events from calendar",
+	MsgGetEventError:             "Can't get event from calendar",
+	MsgCreateEventError:          "Can't create event in calendar",
+	MsgSaveEventError:            "Can't save event to calendar",
+	MsgEventChangedError:         "Event was changed by someone else, please try again",
+	MsgGetTasksError:             "Can't get tasks from calendar",
+	MsgGetTaskError:              "Can't get task from calendar",
+	MsgSaveTaskError:             "Can't save task to calendar",
+	MsgTaskChangedError:          "Task was changed by someone else, please try again",
+	MsgCalendarNotConnectedError: "User hasn't connected calendar",
+	MsgEventNotFoundError:        "Event not found in calendar object",
+	MsgNotAttendeeError:          "You aren't attendee of event",
+	MsgCalendarHomeSetError:      "Can't get calendars of user, please check login and token",
+
+	MsgSettingsDialogTitle:             "Settings",
+	MsgSettingsDialogSubmit:            "Save",
+	MsgCalendarDialogElement:           "Calendar: ",
+	MsgTimezoneDialogElement:           "Select your timezone",
+	MsgDailyNotifyTimeDialogElement:    "Select time for get daily schedule",
+	MsgDailyNotifyTimeDisableOption:    "Never",
+	MsgChangeStatusOnMeetDialogElement: "Setup 'In meeting' status automatically",
+	MsgReminderOffsetsDialogElement:    "Get notifications before event",
+	MsgReminderOffsetsDialogHelp:       "Comma separated time before event start, 0m means at start",
+	MsgEventAlarmsDialogElement:        "Use reminders of events from calendar",
+	MsgEventAlarmsOffOption:            "Don't use",
+	MsgEventAlarmsInsteadOption:        "Instead of notifications before event",
+	MsgEventAlarmsAdditionalOption:     "In addition to notifications before event",
+	MsgThreadedDialogElement:           "Reply reminders and updates in thread of daily schedule or the first notification of event",
+	MsgShareEventTitlesDialogElement:   "Show titles of my events to colleagues in /calendar busy",
+	MsgWorkingHoursDialogElement:       "Working hours",
+	MsgWorkingHoursDialogHelp:          "Weekdays and time when colleagues can invite you with /calendar findtime",
+	MsgCreateEventDialogTitle:          "Create event",
+	MsgCreateEventDialogSubmit:         "Create",
+	MsgTitleDialogElement:              "Title",
+	MsgEventCalendarDialogElement:      "Calendar",
+	MsgDateDialogElement:               "Date",
+	MsgDateDialogHelp:                  "Date in format dd.MM.yyyy",
+	MsgStartDialogElement:              "Start",
+	MsgDurationDialogElement:           "Duration",
+	MsgDescriptionDialogElement:        "Description",
+	MsgLocationDialogElement:           "Location",
+	MsgAttendeesDialogElement:          "Attendees",
+	MsgAttendeesDialogHelp:             "Comma separated emails",
+
+	MsgTentativeEventMark:   ":grey_question: Tentative",
+	MsgNeedsActionEventMark: ":envelope_with_arrow: Awaiting your response",
+	MsgOverdueTaskMark:      ":warning: Overdue",
+	MsgCancelledEventMark:   ":x: Cancelled",
+
+	MsgAcceptEventAction:    "Accept",
+	MsgTentativeEventAction: "Maybe",
+	MsgDeclineEventAction:   "Decline",
+	MsgEventResponseField:   "Your response",
+	MsgCreateSlotAction:     "Create event",
+	MsgSlotCreatedField:     "Event",
+	MsgSlotCreatedValue:     ":white_check_mark: Created",
+	MsgCompleteTaskAction:   "Mark done",
+	MsgTaskStatusField:      "Status",
+	MsgTaskCompletedValue:   ":white_check_mark: Done",
+
+	MsgAcceptedEventResponse:  ":white_check_mark: Accepted",
+	MsgTentativeEventResponse: ":grey_question: Tentative",
+	MsgDeclinedEventResponse:  ":no_entry_sign: Declined",
+
+	MsgConferenceLinkName: "Conference",
+	MsgJoinConferenceLink: "Join",
+
+	MsgAllDayEventsSubtitle: "###### All day",
+	MsgTimedEventsSubtitle:  "###### Schedule",
+
+	MsgCommandHelp: `###### Mattermost Yandex (CALDav) Calendar Plugin - Slash Command Help
+* |/calendar connect [login] [token]| - Connect your Yandex Calendar with your Mattermost account
+* |/calendar disconnect| - Disable Yandex Calendar integration
+* |/calendar update| - Load updates from Yandex Calendar and show if something added/updated in future
+* |/calendar setting| - Change Mattermost Bot settings
+* |/calendar create| - Create event in your calendar
+* |/calendar findtime @user ~channel [duration] [from] [to]| - Find common free time in working hours and create meeting
+	* |duration| is like 30m or 1h, by default 30 minutes. Time is searched for the next 7 days if dates are not set
+* |/calendar busy @user [date]| - Show busy time of colleague, date is the same as for summary
+* |/calendar week [next]| - Get events of current or next week grouped by day
+* |/calendar agenda [from] [to]| - Get events of dates range grouped by day, range is limited to 31 days
+* |/calendar tasks| - Get open tasks with due date, overdue tasks are marked
+* |/calendar search [query] [from] [to]| - Find events by title, description, location or attendees
+	* Events are searched for the past 30 and the next 90 days if dates are not set, range is limited to 366 days. Quote query if it ends with date-like words, e.g. "review friday"
+* |/calendar channel subscribe [calendar]| - Post daily schedule, updates and reminders of your calendar to the current channel, channel admins only
+	* |/calendar channel unsubscribe [calendar]| stops posting, |/calendar channel list| shows calendars of channel
+* |/calendar summary [date]| - Get a break down of a particular date.
+	* |date| can be dd.MM.yyyy, dd.MM, yyyy-MM-dd, "yesterday", "today", "tomorrow", weekday like "friday" or "next friday", offset like "+2" or "in 3 days", Russian words like "завтра", "пятница", "через 3 дня" work too. By default retrieves today's summary breakdown
+`,
+
+	MsgCommandDescription:           "Available commands: connect, list, summary, week, agenda, create, busy, findtime, channel, help",
+	MsgConnectCommandDescription:    "Connect your Yandex Calendar with your login and token",
+	MsgDisconnectCommandDescription: "Disable Yandex Calendar integration",
+	MsgUpdateCommandDescription:     "Update events from server",
+	MsgSettingsCommandDescription:   "Change calendar and notification settings for bot",
+	MsgSummaryCommandDescription:    "Get a breakdown of a particular date",
+	MsgSummaryCommandArgument:       "The date to view, e.g. dd.MM.yyyy, tomorrow, friday or +2",
+	MsgWeekCommandDescription:       "Get events of current or next week",
+	MsgWeekCommandArgument:          "Week",
+	MsgNextWeekCommandItem:          "Next week",
+	MsgAgendaCommandDescription:     "Get events of dates range",
+	MsgAgendaCommandArgument:        "Dates range, e.g. monday friday or 01.03.2024 15.03.2024",
+	MsgSearchCommandDescription:     "Find events by text",
+	MsgSearchCommandArgument:        "Text to find and optional dates range, e.g. review or \"design review\" +1w",
+	MsgTasksCommandDescription:      "Get open tasks with due date",
+	MsgCreateCommandDescription:     "Create event in your calendar",
+	MsgBusyCommandDescription:       "Show busy time of colleague",
+	MsgBusyCommandArgument:          "Colleague and the date to view, e.g. dd.MM.yyyy, tomorrow or friday",
+	MsgFindTimeCommandDescription:   "Find common free time with colleagues",
+	MsgFindTimeCommandArgument:      "Colleagues or channel, meeting duration and dates, e.g. @user 1h tomorrow friday",
+	MsgHelpCommandDescription:       "Display usage",
+
+	MsgChannelCommandDescription:     "Post events of calendar to the current channel",
+	MsgSubscribeCommandDescription:   "Subscribe the current channel to your calendar",
+	MsgUnsubscribeCommandDescription: "Unsubscribe the current channel from calendar",
+	MsgListChannelCommandDescription: "Show calendars subscribed in the current channel",
+	MsgChannelCalendarArgument:       "Name of calendar",
+
+	util.WrongDateMessage:         "Can't parse date",
+	util.WrongDatesMessage:        "Can't parse dates",
+	util.EmptyDateMessage:         "Date is empty",
+	util.WrongDurationMessage:     "Wrong duration",
+	util.WrongWorkingHoursMessage: "Wrong working hours",
+}
@@ -0,0 +1,198 @@
+package conf
+
+import "github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+
+var ruMessages = map[MessageId]string{
+	MsgYesterdayEventsTitle: "##### :calendar: Вчера",
+	MsgTomorrowEventsTitle:  "##### :calendar: Завтра",
+	MsgTodayEventsTitle:     "##### :calendar: Сегодня",
+	MsgAddedEventsTitle:     "##### :new: Новые события",
+	MsgUpdatedEventsTitle:   "##### :arrows_counterclockwise: Изменённые события",
+	MsgRemovedEventsTitle:   "##### :x: Удалённые/отменённые события",
+	MsgCreatedEventTitle:    "##### :white_check_mark: Событие создано",
+	MsgBusyEventsTitle:      "##### :no_entry: Занятость @",
+	MsgFreeSlotsTitle:       "##### :handshake: Общее свободное время",
+	MsgWeekEventsTitle:      "#### :spiral_calendar_pad: Неделя",
+	MsgNextWeekEventsTitle:  "#### :spiral_calendar_pad: Следующая неделя",
+	MsgAgendaEventsTitle:    "#### :spiral_calendar_pad: Расписание",
+	MsgReminderEventTitle:   "##### :alarm_clock: %s до события",
+	MsgReminderStartedTitle: "##### :alarm_clock: Событие начинается",
+	MsgAlarmEventTitle:      "##### :bell: %s до события",
+	MsgAlarmStartedTitle:    "##### :bell: Событие началось",
+	MsgAlarmEndTitle:        "##### :bell: %s до окончания события",
+	MsgAlarmEndedTitle:      "##### :bell: Событие закончилось",
+	MsgSearchEventsTitle:    "#### :mag: Поиск \"%s\"",
+	MsgTasksTitle:           "#### :ballot_box_with_check: Задачи",
+	MsgTodayTasksTitle:      "##### :ballot_box_with_check: Задачи на сегодня",
+	MsgTaskDueTitle:         "##### :hourglass: Срок задачи наступил",
+
+	MsgFreeDayMessage:        ":palm_tree: Нет занятого времени",
+	MsgNoFreeSlotsMessage:    "Нет общего свободного времени в рабочие часы",
+	MsgSlotEventName:         "Встреча",
+	MsgBusyIntervalName:      "Занят",
+	MsgNoTasksMessage:        "Нет открытых задач со сроком",
+	MsgColleagueNotConnected: "@%s не подключил Яндекс Календарь",
+	MsgNotConnectedMessage:   "Подключите календарь командой **/calendar connect [login] [token]** и выберите календари в **/calendar settings**",
+	MsgDateFormatsHelp: "Используйте dd.MM.yyyy, dd.MM, yyyy-MM-dd, сегодня, завтра, вчера, день недели, например пятница " +
+		"или следующая пятница, смещение, например +2 или через 3 дня. Английские слова тоже подходят: tomorrow, friday, in 3 days",
+	MsgWelcomeMessage: "#### Добро пожаловать в плагин Яндекс Календаря для Mattermost!\n" +
+		"Наберите **/calendar help**, чтобы узнать, как пользоваться плагином. ",
+	MsgWrongCommandMessage: "Неверный формат команды. Прочитайте " +
+		"**[инструкцию](https://github.com/LugaMuga/mattermost-yandex-calendar-plugin/blob/master/docs/readme.md)**",
+	MsgByeMessage:                  "До свидания :wave:",
+	MsgNoEventsMessage:             "Нет событий",
+	MsgLoadEventsErrorMessage:      ":no_entry_sign: Ошибка при загрузке событий",
+	MsgLoadTasksErrorMessage:       ":no_entry_sign: Ошибка при загрузке задач",
+	MsgWrongRangeMessage:           "Конец периода должен быть после его начала и не слишком далеко от него",
+	MsgSearchQueryRequiredMessage:  "Укажите текст для поиска: **/calendar search [query] [from] [to]**",
+	MsgColleagueRequiredMessage:    "Укажите коллегу: **/calendar busy @user [date]**",
+	MsgParticipantsRequiredMessage: "Укажите подключённых коллег: **/calendar findtime @user ~channel [duration] [from] [to]**",
+	MsgUserNotFoundMessage:         "Пользователь @%s не найден",
+	MsgChannelNotFoundMessage:      "Канал ~%s не найден",
+	MsgNotChannelMemberMessage:     "Вы не участник канала ~%s",
+	MsgChannelMembersErrorMessage:  "Не удалось получить участников канала ~%s",
+	MsgNoCalendarSelectedMessage:   "Выберите хотя бы один календарь",
+	MsgWrongDialogDateMessage:      "Используйте формат dd.MM.yyyy или слова, например завтра, пятница",
+	MsgStartRequiredMessage:        "Выберите время начала",
+	MsgDurationRequiredMessage:     "Выберите длительность",
+	MsgCalendarRequiredMessage:     "Выберите календарь",
+	MsgWrongEmailMessage:           "Неверный email: %s",
+	MsgInMeetingStatus:             "На встрече",
+
+	MsgWrongChannelCommandMessage:    "Используйте **/calendar channel subscribe [calendar]**, **/calendar channel unsubscribe [calendar]** или **/calendar channel list**",
+	MsgDirectChannelMessage:          "Календари можно подписать только в публичных и приватных каналах",
+	MsgNotChannelAdminMessage:        "Только администраторы канала могут менять календари канала",
+	MsgChannelCalendarNotFound:       "Календарь **%s** не найден, ваши календари: %s",
+	MsgChannelCalendarSubscribed:     "Календарь **%s** уже подписан в этом канале",
+	MsgChannelCalendarNotSubscribed:  "Календарь **%s** не подписан в этом канале",
+	MsgSubscribeChannelErrorMessage:  ":no_entry_sign: Не удалось подписать канал на календарь",
+	MsgChannelSubscribedMessage:      ":spiral_calendar_pad: @%s подписал канал на календарь **%s**. Сюда будут приходить расписание на день, изменения событий и напоминания о начале событий",
+	MsgChannelUnsubscribedMessage:    ":spiral_calendar_pad: @%s отписал канал от календаря **%s**",
+	MsgChannelSubscriptionsTitle:     "##### :spiral_calendar_pad: Календари канала",
+	MsgNoChannelSubscriptionsMessage: "Канал не подписан ни на один календарь",
+
+	MsgCalendarClientError:       "Не удалось подключиться к календарю",
+	MsgGetEventsError:            "Не удалось получить события из календаря",
+	MsgGetEventError:             "Не удалось получить событие из календаря",
+	MsgCreateEventError:          "Не удалось создать событие в календаре",
+	MsgSaveEventError:            "Не удалось сохранить событие в календаре",
+	MsgEventChangedError:         "Событие изменил кто-то другой, попробуйте ещё раз",
+	MsgGetTasksError:             "Не удалось получить задачи из календаря",
+	MsgGetTaskError:              "Не удалось получить задачу из календаря",
+	MsgSaveTaskError:             "Не удалось сохранить задачу в календаре",
+	MsgTaskChangedError:          "Задачу изменил кто-то другой, попробуйте ещё раз",
+	MsgCalendarNotConnectedError: "Пользователь не подключил календарь",
+	MsgEventNotFoundError:        "Событие не найдено в календаре",
+	MsgNotAttendeeError:          "Вы не участник события",
+	MsgCalendarHomeSetError:      "Не удалось получить календари пользователя, проверьте логин и токен",
+
+	MsgSettingsDialogTitle:             "Настройки",
+	MsgSettingsDialogSubmit:            "Сохранить",
+	MsgCalendarDialogElement:           "Календарь: ",
+	MsgTimezoneDialogElement:           "Выберите часовой пояс",
+	MsgDailyNotifyTimeDialogElement:    "Выберите время получения расписания на день",
+	MsgChangeStatusOnMeetDialogElement: "Ставить статус 'На встрече' автоматически",
+	MsgReminderOffsetsDialogElement:    "Уведомлять до начала события",
+	MsgReminderOffsetsDialogHelp:       "Время до начала события через запятую, 0m означает в момент начала",
+	MsgEventAlarmsDialogElement:        "Использовать напоминания событий из календаря",
+	MsgEventAlarmsOffOption:            "Не использовать",
+	MsgEventAlarmsInsteadOption:        "Вместо уведомлений до начала события",
+	MsgEventAlarmsAdditionalOption:     "Вместе с уведомлениями до начала события",
+	MsgThreadedDialogElement:           "Отвечать напоминаниями и изменениями в треде расписания на день или первого уведомления о событии",
+	MsgShareEventTitlesDialogElement:   "Показывать названия моих событий коллегам в /calendar busy",
+	MsgWorkingHoursDialogElement:       "Рабочие часы",
+	MsgWorkingHoursDialogHelp:          "Дни недели и время, когда коллеги могут пригласить вас через /calendar findtime",
+	MsgCreateEventDialogTitle:          "Создать событие",
+	MsgCreateEventDialogSubmit:         "Создать",
+	MsgTitleDialogElement:              "Название",
+	MsgEventCalendarDialogElement:      "Календарь",
+	MsgDateDialogElement:               "Дата",
+	MsgDateDialogHelp:                  "Дата в формате dd.MM.yyyy",
+	MsgStartDialogElement:              "Начало",
+	MsgDurationDialogElement:           "Длительность",
+	MsgDescriptionDialogElement:        "Описание",
+	MsgLocationDialogElement:           "Место",
+	MsgAttendeesDialogElement:          "Участники",
+	MsgAttendeesDialogHelp:             "Email участников через запятую",
+	MsgDailyNotifyTimeDisableOption:    "Никогда",
+
+	MsgTentativeEventMark:   ":grey_question: Под вопросом",
+	MsgNeedsActionEventMark: ":envelope_with_arrow: Ожидает вашего ответа",
+	MsgOverdueTaskMark:      ":warning: Просрочена",
+	MsgCancelledEventMark:   ":x: Отменено",
+
+	MsgAcceptEventAction:    "Принять",
+	MsgTentativeEventAction: "Возможно",
+	MsgDeclineEventAction:   "Отклонить",
+	MsgCreateSlotAction:     "Создать событие",
+	MsgEventResponseField:   "Ваш ответ",
+	MsgSlotCreatedField:     "Событие",
+	MsgSlotCreatedValue:     ":white_check_mark: Создано",
+	MsgCompleteTaskAction:   "Выполнено",
+	MsgTaskStatusField:      "Статус",
+	MsgTaskCompletedValue:   ":white_check_mark: Выполнена",
+
+	MsgAcceptedEventResponse:  ":white_check_mark: Принято",
+	MsgTentativeEventResponse: ":grey_question: Под вопросом",
+	MsgDeclinedEventResponse:  ":no_entry_sign: Отклонено",
+
+	MsgConferenceLinkName:   "Видеовстреча",
+	MsgJoinConferenceLink:   "Подключиться",
+	MsgAllDayEventsSubtitle: "###### Весь день",
+	MsgTimedEventsSubtitle:  "###### Расписание",
+
+	util.WrongDateMessage:     "Не удалось распознать дату",
+	util.WrongDatesMessage:    "Не удалось распознать даты",
+	util.EmptyDateMessage:     "Дата не указана",
+	util.WrongDurationMessage: "Неверная длительность",
+
+	util.WrongWorkingHoursMessage: "Неверные рабочие часы",
+
+	MsgCommandHelp: `###### Плагин Яндекс (CALDav) Календаря для Mattermost - справка по командам
+* |/calendar connect [login] [token]| - Подключить Яндекс Календарь к аккаунту Mattermost
+* |/calendar disconnect| - Отключить Яндекс Календарь
+* |/calendar update| - Загрузить изменения из Яндекс Календаря и показать новые и изменённые события
+* |/calendar setting| - Изменить настройки бота
+* |/calendar create| - Создать событие в календаре
+* |/calendar findtime @user ~channel [duration] [from] [to]| - Найти общее свободное время в рабочие часы и создать встречу
+	* |duration| задаётся как 30m или 1h, по умолчанию 30 минут. Если даты не указаны, время ищется на 7 дней вперёд
+* |/calendar busy @user [date]| - Показать занятость коллеги, дата задаётся как для summary
+* |/calendar week [next]| - Показать события текущей или следующей недели по дням
+* |/calendar agenda [from] [to]| - Показать события периода по дням, период не длиннее 31 дня
+* |/calendar tasks| - Показать открытые задачи со сроком, просроченные задачи отмечены
+* |/calendar search [query] [from] [to]| - Найти события по названию, описанию, месту или участникам
+	* Если даты не указаны, поиск идёт за прошедшие 30 и следующие 90 дней, период не длиннее 366 дней. Возьмите запрос в кавычки, если он заканчивается словами, похожими на дату, например "обзор пятница"
+* |/calendar channel subscribe [calendar]| - Публиковать расписание на день, изменения и напоминания вашего календаря в текущем канале, только для администраторов канала
+	* |/calendar channel unsubscribe [calendar]| прекращает публикацию, |/calendar channel list| показывает календари канала
+* |/calendar summary [date]| - Показать события выбранного дня.
+	* |date| может быть dd.MM.yyyy, dd.MM, yyyy-MM-dd, "вчера", "сегодня", "завтра", день недели, например "пятница" или "следующая пятница", смещение, например "+2" или "через 3 дня", английские слова тоже подходят. По умолчанию показываются события сегодняшнего дня
+`,
+
+	MsgCommandDescription:           "Доступные команды: connect, list, summary, week, agenda, create, busy, findtime, channel, help",
+	MsgConnectCommandDescription:    "Подключить Яндекс Календарь по логину и токену",
+	MsgDisconnectCommandDescription: "Отключить Яндекс Календарь",
+	MsgUpdateCommandDescription:     "Загрузить изменения событий с сервера",
+	MsgSettingsCommandDescription:   "Изменить календари и настройки уведомлений бота",
+	MsgSummaryCommandDescription:    "Показать события выбранного дня",
+	MsgSummaryCommandArgument:       "Дата, например dd.MM.yyyy, завтра, пятница или +2",
+	MsgWeekCommandDescription:       "Показать события текущей или следующей недели",
+	MsgWeekCommandArgument:          "Неделя",
+	MsgNextWeekCommandItem:          "Следующая неделя",
+	MsgAgendaCommandDescription:     "Показать события периода",
+	MsgAgendaCommandArgument:        "Период, например понедельник пятница или 01.03.2024 15.03.2024",
+	MsgSearchCommandDescription:     "Найти события по тексту",
+	MsgSearchCommandArgument:        "Текст для поиска и необязательный период, например обзор или \"обзор дизайна\" +1w",
+	MsgTasksCommandDescription:      "Показать открытые задачи со сроком",
+	MsgCreateCommandDescription:     "Создать событие в календаре",
+	MsgBusyCommandDescription:       "Показать занятость коллеги",
+	MsgBusyCommandArgument:          "Коллега и дата, например dd.MM.yyyy, завтра или пятница",
+	MsgFindTimeCommandDescription:   "Найти общее свободное время с коллегами",
+	MsgFindTimeCommandArgument:      "Коллеги или канал, длительность встречи и даты, например @user 1h завтра пятница",
+	MsgHelpCommandDescription:       "Показать справку",
+
+	MsgChannelCommandDescription:     "Публиковать события календаря в текущем канале",
+	MsgSubscribeCommandDescription:   "Подписать текущий канал на ваш календарь",
+	MsgUnsubscribeCommandDescription: "Отписать текущий канал от календаря",
+	MsgListChannelCommandDescription: "Показать календари текущего канала",
+	MsgChannelCalendarArgument:       "Название календаря",
+}
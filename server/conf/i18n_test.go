@@ -0,0 +1,41 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+)
+
+func TestBundlesHaveSameMessages(t *testing.T) {
+	for id := range enMessages {
+		if _, ok := ruMessages[id]; !ok {
+			t.Errorf("message %s has no Russian translation", id)
+		}
+	}
+	for id := range ruMessages {
+		if _, ok := enMessages[id]; !ok {
+			t.Errorf("Russian message %s is missing in English bundle", id)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		id       MessageId
+		want     string
+	}{
+		{"english", util.LanguageEn, MsgTentativeEventAction, "Maybe"},
+		{"russian", util.LanguageRu, MsgTentativeEventAction, "Возможно"},
+		{"unknown language", "de", MsgTentativeEventAction, "Maybe"},
+		{"unknown message", util.LanguageRu, "unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.language, tt.id); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
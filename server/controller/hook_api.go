@@ -5,6 +5,7 @@ import (
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/service"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
 	"github.com/mattermost/mattermost-plugin-api/experimental/command"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin"
@@ -13,23 +14,14 @@ import (
 	"time"
 )
 
-const summaryDateFormat = "01.15.2006"
-
-//CommandHelp - about
-const CommandHelp = `###### Mattermost Yandex (CALDav) Calendar Plugin - Slash Command Help
-* |/calendar connect [login] [token]| - Connect your Yandex Calendar with your Mattermost account
-* |/calendar disconnect| - Disable Yandex Calendar integration
-* |/calendar update| - Load updates from Yandex Calendar and show if something added/updated in future
-* |/calendar setting| - Change Mattermost Bot settings
-* |/calendar summary [date]| - Get a break down of a particular date.
-	* |date| should be a date in the format of dd.MM.YYYY or can be "yesterday", "today", "tomorrow" or can be left blank. By default retrieves today's summary breakdown
-`
+const channelMembersPerPage = 100
 
 type HookController struct {
 	pluginAPI plugin.API
 	botId     string
 	calendar  *service.Calendar
 	user      *service.User
+	channel   *service.Channel
 	sender    *service.Sender
 	scheduler *service.Scheduler
 	workspace *service.Workspace
@@ -40,6 +32,7 @@ func NewHookController(
 	botId string,
 	calendar *service.Calendar,
 	user *service.User,
+	channel *service.Channel,
 	sender *service.Sender,
 	scheduler *service.Scheduler,
 	workspace *service.Workspace) *HookController {
@@ -48,6 +41,7 @@ func NewHookController(
 		botId:     botId,
 		calendar:  calendar,
 		user:      user,
+		channel:   channel,
 		sender:    sender,
 		scheduler: scheduler,
 		workspace: workspace,
@@ -79,77 +73,144 @@ func (hc *HookController) ExecuteCommand(c *plugin.Context, args *model.CommandA
 		hc.settings(args)
 	case "summary":
 		hc.summary(args)
+	case "week":
+		hc.week(args)
+	case "agenda":
+		hc.agenda(args)
+	case "tasks":
+		hc.tasks(args)
+	case "search":
+		hc.search(args)
+	case "create":
+		hc.create(args)
+	case "busy":
+		hc.busy(args)
+	case "findtime":
+		hc.findtime(args)
+	case "channel":
+		hc.manageChannel(args)
 	case "help":
 		hc.help(args)
 	}
 	return &model.CommandResponse{}, nil
 }
 
+// GetHookCommand describes command in default language of server, as command is registered for all users
 func GetHookCommand(pluginAPI plugin.API) (*model.Command, error) {
 	iconData, err := command.GetIconData(pluginAPI, "assets/icon.svg")
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get icon data")
 	}
+	language := util.LanguageEn
+	if locale := pluginAPI.GetConfig().LocalizationSettings.DefaultClientLocale; locale != nil {
+		language = util.GetLanguage(*locale)
+	}
 
 	return &model.Command{
 		Trigger:              "calendar",
 		DisplayName:          "Google Calendar",
 		Description:          "Integration with Google Calendar",
 		AutoComplete:         true,
-		AutoCompleteDesc:     "Available commands: connect, list, summary, create, help",
+		AutoCompleteDesc:     conf.T(language, conf.MsgCommandDescription),
 		AutoCompleteHint:     "[command]",
-		AutocompleteData:     getAutocompleteData(),
+		AutocompleteData:     getAutocompleteData(language),
 		AutocompleteIconData: iconData,
 	}, nil
 }
 
-func getAutocompleteData() *model.AutocompleteData {
-	cal := model.NewAutocompleteData("calendar", "[command]", "Available commands: connect, list, summary, create, help")
+func getAutocompleteData(language string) *model.AutocompleteData {
+	cal := model.NewAutocompleteData("calendar", "[command]", conf.T(language, conf.MsgCommandDescription))
 
-	connect := model.NewAutocompleteData("connect", "[login] [token]", "Connect your Yandex Calendar with your login and token")
+	connect := model.NewAutocompleteData("connect", "[login] [token]", conf.T(language, conf.MsgConnectCommandDescription))
 	cal.AddCommand(connect)
 
-	disconnect := model.NewAutocompleteData("disconnect", "", "Disable Yandex Calendar integration")
+	disconnect := model.NewAutocompleteData("disconnect", "", conf.T(language, conf.MsgDisconnectCommandDescription))
 	cal.AddCommand(disconnect)
 
-	update := model.NewAutocompleteData("update", "", "Update events from server")
+	update := model.NewAutocompleteData("update", "", conf.T(language, conf.MsgUpdateCommandDescription))
 	cal.AddCommand(update)
 
-	settings := model.NewAutocompleteData("settings", "", "Change calendar and notification settings for bot")
+	settings := model.NewAutocompleteData("settings", "", conf.T(language, conf.MsgSettingsCommandDescription))
 	cal.AddCommand(settings)
 
-	summary := model.NewAutocompleteData("summary", "[date]", "Get a breakdown of a particular date")
-	summary.AddTextArgument("The date to view in dd.MM.YYYY format", "[date]", "")
+	summary := model.NewAutocompleteData("summary", "[date]", conf.T(language, conf.MsgSummaryCommandDescription))
+	summary.AddTextArgument(conf.T(language, conf.MsgSummaryCommandArgument), "[date]", "")
 	cal.AddCommand(summary)
 
-	help := model.NewAutocompleteData("help", "", "Display usage")
+	week := model.NewAutocompleteData("week", "[next]", conf.T(language, conf.MsgWeekCommandDescription))
+	week.AddStaticListArgument(conf.T(language, conf.MsgWeekCommandArgument), false, []model.AutocompleteListItem{
+		{Item: "next", HelpText: conf.T(language, conf.MsgNextWeekCommandItem)},
+	})
+	cal.AddCommand(week)
+
+	agenda := model.NewAutocompleteData("agenda", "[from] [to]", conf.T(language, conf.MsgAgendaCommandDescription))
+	agenda.AddTextArgument(conf.T(language, conf.MsgAgendaCommandArgument), "[from] [to]", "")
+	cal.AddCommand(agenda)
+
+	search := model.NewAutocompleteData("search", "[query] [from] [to]", conf.T(language, conf.MsgSearchCommandDescription))
+	search.AddTextArgument(conf.T(language, conf.MsgSearchCommandArgument), "[query] [from] [to]", "")
+	cal.AddCommand(search)
+
+	tasks := model.NewAutocompleteData("tasks", "", conf.T(language, conf.MsgTasksCommandDescription))
+	cal.AddCommand(tasks)
+
+	create := model.NewAutocompleteData("create", "", conf.T(language, conf.MsgCreateCommandDescription))
+	cal.AddCommand(create)
+
+	busy := model.NewAutocompleteData("busy", "@user [date]", conf.T(language, conf.MsgBusyCommandDescription))
+	busy.AddTextArgument(conf.T(language, conf.MsgBusyCommandArgument), "@user [date]", "")
+	cal.AddCommand(busy)
+
+	findtime := model.NewAutocompleteData("findtime", "@user ~channel [duration] [from] [to]", conf.T(language, conf.MsgFindTimeCommandDescription))
+	findtime.AddTextArgument(conf.T(language, conf.MsgFindTimeCommandArgument), "@user ~channel [duration] [from] [to]", "")
+	cal.AddCommand(findtime)
+
+	channel := model.NewAutocompleteData("channel", "[command]", conf.T(language, conf.MsgChannelCommandDescription))
+	subscribe := model.NewAutocompleteData("subscribe", "[calendar]", conf.T(language, conf.MsgSubscribeCommandDescription))
+	subscribe.AddTextArgument(conf.T(language, conf.MsgChannelCalendarArgument), "[calendar]", "")
+	channel.AddCommand(subscribe)
+	unsubscribe := model.NewAutocompleteData("unsubscribe", "[calendar]", conf.T(language, conf.MsgUnsubscribeCommandDescription))
+	unsubscribe.AddTextArgument(conf.T(language, conf.MsgChannelCalendarArgument), "[calendar]", "")
+	channel.AddCommand(unsubscribe)
+	channel.AddCommand(model.NewAutocompleteData("list", "", conf.T(language, conf.MsgListChannelCommandDescription)))
+	cal.AddCommand(channel)
+
+	help := model.NewAutocompleteData("help", "", conf.T(language, conf.MsgHelpCommandDescription))
 	cal.AddCommand(help)
 	return cal
 }
 
 func (hc *HookController) connect(args *model.CommandArgs) {
 	split := strings.Fields(args.Command)
+	language := hc.sender.GetLanguage(args.UserId)
 	if len(split) < 4 {
-		hc.sender.SendBotDMPost(args.UserId, "Wrong command format. Please read **[instruction](https://github.com/LugaMuga/mattermost-yandex-calendar-plugin/blob/master/docs/readme.md)**")
+		hc.sender.SendBotDMPost(args.UserId, conf.T(language, conf.MsgWrongCommandMessage))
 		return
 	}
 	credentials := &dto.Credentials{
 		Login: split[2],
 		Token: split[3],
 	}
-	hc.user.Connect(args.UserId, args.TriggerId, args.RootId, *credentials)
+	hc.user.Connect(args.UserId, language, args.TriggerId, args.RootId, *credentials)
 }
 
 func (hc *HookController) settings(args *model.CommandArgs) {
-	hc.user.Settings(args.UserId, args.TriggerId, args.RootId)
+	hc.user.Settings(args.UserId, hc.sender.GetLanguage(args.UserId), args.TriggerId, args.RootId)
+}
+
+func (hc *HookController) create(args *model.CommandArgs) {
+	hc.user.OpenCreateEventDialog(args.UserId, hc.sender.GetLanguage(args.UserId), args.TriggerId, args.RootId)
 }
 
 func (hc *HookController) disconnect(args *model.CommandArgs) {
 	userId := args.UserId
 	hc.scheduler.DeleteCronJobs(userId)
+	for _, channelId := range hc.channel.DeleteUserSubscriptions(userId) {
+		hc.scheduler.DeleteChannelCronJobs(channelId)
+	}
 	hc.workspace.DeleteUser(userId)
-	hc.sender.SendBotDMPost(userId, "Bye, bye :wave:")
+	hc.sender.SendBotDMPost(userId, conf.T(hc.sender.GetLanguage(userId), conf.MsgByeMessage))
 }
 
 func (hc *HookController) update(args *model.CommandArgs) {
@@ -164,49 +225,485 @@ func (hc *HookController) summary(args *model.CommandArgs) {
 	if userSettings == nil || userSettings.TimeZone == "" {
 		return
 	}
-	day := "today"
-	if len(split) >= 3 {
-		day = split[2]
+	language := hc.sender.GetLanguage(userId)
+	start, end, title, err := getDayRange(split[2:], userSettings.GetUserLocation(), language)
+	if err != nil {
+		hc.sendDateError(userId, language, err)
+		return
+	}
+
+	events, err := hc.calendar.GetEvents(userId, start, end)
+	if err != nil {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadEventsErrorMessage))
+		return
 	}
 
+	hc.calendar.SortEvents(events)
+	hc.sender.SendEvents(userId, language, title, events)
+}
+
+func (hc *HookController) week(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		return
+	}
 	userNow := time.Now().In(userSettings.GetUserLocation())
-	var start, end time.Time
-	var title string
-
-	switch day {
-	case "yesterday":
-		yesterday := userNow.AddDate(0, 0, -1)
-		start = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, yesterday.Location())
-		end = time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 23, 59, 59, 0, yesterday.Location())
-		title = conf.GetEventsTitle(conf.YesterdayEventsTitle, start)
-	case "today":
-		start, end = hc.calendar.GetTodayDateTimes(userId)
-		title = conf.GetEventsTitle(conf.TodayEventsTitle, start)
-	case "tomorrow":
-		tomorrow := userNow.AddDate(0, 0, 1)
-		start = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, tomorrow.Location())
-		end = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 23, 59, 59, 0, tomorrow.Location())
-		title = conf.GetEventsTitle(conf.TomorrowEventsTitle, start)
-	default:
-		date, err := time.ParseInLocation(summaryDateFormat, split[2], userSettings.GetUserLocation())
+	daysFromMonday := (int(userNow.Weekday()) + 6) % 7
+	start := time.Date(userNow.Year(), userNow.Month(), userNow.Day()-daysFromMonday, 0, 0, 0, 0, userNow.Location())
+	name := conf.MsgWeekEventsTitle
+	if len(split) >= 3 && split[2] == "next" {
+		start = start.AddDate(0, 0, 7)
+		name = conf.MsgNextWeekEventsTitle
+	}
+	end := time.Date(start.Year(), start.Month(), start.Day()+6, 23, 59, 59, 0, start.Location())
+	language := hc.sender.GetLanguage(userId)
+	hc.sendAgenda(userId, language, conf.GetRangeEventsTitle(language, conf.T(language, name), start, end), start, end)
+}
+
+func (hc *HookController) agenda(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		return
+	}
+	language := hc.sender.GetLanguage(userId)
+	start, end, err := getDaysRange(split[2:], userSettings.GetUserLocation())
+	if err != nil {
+		hc.sendDateError(userId, language, err)
+		return
+	}
+	if isRangeLongerThan(start, end, conf.AgendaMaxDays) {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgWrongRangeMessage))
+		return
+	}
+	hc.sendAgenda(userId, language, conf.GetRangeEventsTitle(language, conf.T(language, conf.MsgAgendaEventsTitle), start, end), start, end)
+}
+
+func (hc *HookController) tasks(args *model.CommandArgs) {
+	userId := args.UserId
+	language := hc.sender.GetLanguage(userId)
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+	tasks, err := hc.calendar.LoadTasks(userId)
+	if err != nil {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadTasksErrorMessage))
+		return
+	}
+	hc.sender.SendTasks(userId, language, "", conf.T(language, conf.MsgTasksTitle), tasks, userSettings.GetUserNow())
+}
+
+func (hc *HookController) search(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	language := hc.sender.GetLanguage(userId)
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+	query, dateWords := splitSearchQuery(split[2:])
+	if query == "" {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgSearchQueryRequiredMessage))
+		return
+	}
+	userNow := time.Now().In(userSettings.GetUserLocation())
+	today := time.Date(userNow.Year(), userNow.Month(), userNow.Day(), 0, 0, 0, 0, userNow.Location())
+	start := today.AddDate(0, 0, -conf.SearchDefaultPastDays)
+	end := time.Date(today.Year(), today.Month(), today.Day()+conf.SearchDefaultFutureDays, 23, 59, 59, 0, today.Location())
+	if len(dateWords) > 0 {
+		var err error
+		start, end, err = getDaysRange(dateWords, userSettings.GetUserLocation())
 		if err != nil {
-			hc.sender.SendBotDMPost(userId, "Can't parse date. Please use format dd.MM.YYYY")
+			hc.sendDateError(userId, language, err)
+			return
+		}
+		if isRangeLongerThan(start, end, conf.SearchMaxDays) {
+			hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgWrongRangeMessage))
+			return
 		}
-		start = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-		end = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
-		title = conf.GetEventsTitle("", start)
 	}
 
-	events, err := hc.calendar.LoadEvents(userId, start, end)
+	events, err := hc.calendar.SearchEvents(userId, query, start, end)
 	if err != nil {
-		hc.sender.SendBotDMPost(userId, ":no_entry_sign: Catch error on load events")
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadEventsErrorMessage))
 		return
 	}
+	title := conf.GetRangeEventsTitle(language, conf.Tf(language, conf.MsgSearchEventsTitle, query), start, end)
+	hc.sender.SendAgenda(userId, language, title, start, end, events)
+}
+
+// splitSearchQuery separates query from dates range at the end, quoted query is taken as is
+func splitSearchQuery(words []string) (string, []string) {
+	if len(words) > 0 && strings.HasPrefix(words[0], "\"") {
+		for i := range words {
+			if (i > 0 || len(words[0]) > 1) && strings.HasSuffix(words[i], "\"") {
+				return strings.Trim(strings.Join(words[:i+1], " "), "\""), words[i+1:]
+			}
+		}
+		return strings.Trim(strings.Join(words, " "), "\""), nil
+	}
+	for i := 1; i < len(words); i++ {
+		// Reversed dates are split too, so their error is reported instead of searching them as text
+		_, _, err := util.ParseDateRange(words[i:], time.Now())
+		if inputErr, ok := err.(*util.InputError); err == nil || ok && inputErr.Message == util.WrongRangeMessage {
+			return strings.Join(words[:i], " "), words[i:]
+		}
+	}
+	return strings.Join(words, " "), nil
+}
 
+func (hc *HookController) sendAgenda(userId string, language string, title string, start time.Time, end time.Time) {
+	events, err := hc.calendar.GetEvents(userId, start, end)
+	if err != nil {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadEventsErrorMessage))
+		return
+	}
 	hc.calendar.SortEvents(events)
-	hc.sender.SendEvents(userId, title, events)
+	hc.sender.SendAgenda(userId, language, title, start, end, events)
+}
+
+func (hc *HookController) busy(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	language := hc.sender.GetLanguage(userId)
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+	if len(split) < 3 {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgColleagueRequiredMessage))
+		return
+	}
+	username := strings.TrimPrefix(split[2], "@")
+	colleague, appErr := hc.pluginAPI.GetUserByUsername(username)
+	if appErr != nil {
+		hc.sender.SendBotDMPost(userId, conf.Tf(language, conf.MsgUserNotFoundMessage, username))
+		return
+	}
+	colleagueSettings := repository.GetSettings(hc.pluginAPI, colleague.Id)
+	if colleagueSettings == nil || len(colleagueSettings.Calendars) == 0 {
+		hc.sender.SendBotDMPost(userId, conf.Tf(language, conf.MsgColleagueNotConnected, username))
+		return
+	}
+	start, end, _, err := getDayRange(split[3:], userSettings.GetUserLocation(), language)
+	if err != nil {
+		hc.sendDateError(userId, language, err)
+		return
+	}
+
+	intervals, err := hc.calendar.LoadBusyIntervals(colleague.Id, start, end)
+	if err != nil {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadEventsErrorMessage))
+		return
+	}
+	title := conf.GetEventsTitle(language, conf.T(language, conf.MsgBusyEventsTitle)+username, start)
+	hc.sender.SendBusyIntervals(userId, language, title, intervals, userSettings.GetUserLocation())
+}
+
+func (hc *HookController) findtime(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	language := hc.sender.GetLanguage(userId)
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	if userSettings == nil || userSettings.TimeZone == "" {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+
+	participantIds := []string{userId}
+	addParticipant := func(participantId string) {
+		for _, id := range participantIds {
+			if id == participantId {
+				return
+			}
+		}
+		participantIds = append(participantIds, participantId)
+	}
+	duration := conf.FindTimeDefaultMinutes * time.Minute
+	var dateWords []string
+	for _, arg := range split[2:] {
+		switch {
+		case strings.HasPrefix(arg, "@"):
+			username := strings.TrimPrefix(arg, "@")
+			colleague, appErr := hc.pluginAPI.GetUserByUsername(username)
+			if appErr != nil {
+				hc.sender.SendBotDMPost(userId, conf.Tf(language, conf.MsgUserNotFoundMessage, username))
+				return
+			}
+			if !hc.isConnected(colleague.Id) {
+				hc.sender.SendBotDMPost(userId, conf.Tf(language, conf.MsgColleagueNotConnected, username))
+				return
+			}
+			addParticipant(colleague.Id)
+		case strings.HasPrefix(arg, "~"):
+			channelName := strings.TrimPrefix(arg, "~")
+			memberIds, err := hc.getChannelMemberIds(args.TeamId, channelName, userId, language)
+			if err != nil {
+				hc.sender.SendBotDMPost(userId, err.Error())
+				return
+			}
+			for _, memberId := range memberIds {
+				if hc.isConnected(memberId) {
+					addParticipant(memberId)
+				}
+			}
+		default:
+			if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+				duration = d
+				continue
+			}
+			dateWords = append(dateWords, arg)
+		}
+	}
+	if len(participantIds) < 2 {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgParticipantsRequiredMessage))
+		return
+	}
+
+	start := time.Now().In(userSettings.GetUserLocation())
+	end := start.AddDate(0, 0, conf.FindTimeDefaultDays)
+	if len(dateWords) > 0 {
+		rangeStart, rangeEnd, err := getDaysRange(dateWords, userSettings.GetUserLocation())
+		if err != nil {
+			hc.sendDateError(userId, language, err)
+			return
+		}
+		if rangeStart.After(start) {
+			start = rangeStart
+		}
+		end = rangeEnd
+	}
+	slots, err := hc.calendar.FindFreeSlots(participantIds, duration, start, end)
+	if err != nil {
+		hc.sender.SendBotDMPost(userId, conf.T(language, conf.MsgLoadEventsErrorMessage))
+		return
+	}
+	hc.sender.SendFreeSlots(userId, language, slots, participantIds, userSettings.GetUserLocation())
+}
+
+func (hc *HookController) isConnected(userId string) bool {
+	userSettings := repository.GetSettings(hc.pluginAPI, userId)
+	return userSettings != nil && len(userSettings.Calendars) > 0
+}
+
+// manageChannel subscribes the current channel to calendars of user, only channel admins can change subscriptions
+func (hc *HookController) manageChannel(args *model.CommandArgs) {
+	split := strings.Fields(args.Command)
+	userId := args.UserId
+	language := hc.sender.GetLanguage(userId)
+	if len(split) < 3 {
+		hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.T(language, conf.MsgWrongChannelCommandMessage))
+		return
+	}
+	channel, appErr := hc.pluginAPI.GetChannel(args.ChannelId)
+	if appErr != nil {
+		hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.Tf(language, conf.MsgChannelNotFoundMessage, args.ChannelId))
+		return
+	}
+	if channel.IsGroupOrDirect() {
+		hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.T(language, conf.MsgDirectChannelMessage))
+		return
+	}
+	calendarName := strings.Trim(strings.Join(split[3:], " "), "\"")
+	switch split[2] {
+	case "list":
+		hc.listChannelCalendars(userId, language, channel.Id)
+	case "subscribe", "unsubscribe":
+		if calendarName == "" {
+			hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.T(language, conf.MsgWrongChannelCommandMessage))
+			return
+		}
+		if !hc.isChannelAdmin(channel.Id, userId) {
+			hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.T(language, conf.MsgNotChannelAdminMessage))
+			return
+		}
+		if split[2] == "subscribe" {
+			hc.subscribeChannel(userId, language, channel.Id, calendarName)
+		} else {
+			hc.unsubscribeChannel(userId, language, channel.Id, calendarName)
+		}
+	default:
+		hc.sender.SendEphemeralPost(userId, args.ChannelId, conf.T(language, conf.MsgWrongChannelCommandMessage))
+	}
+}
+
+func (hc *HookController) subscribeChannel(userId string, language string, channelId string, calendarName string) {
+	if !hc.isConnected(userId) {
+		hc.sender.SendEphemeralPost(userId, channelId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+	calendars, err := hc.calendar.FindCalendars(userId)
+	if err != nil {
+		hc.sender.SendEphemeralPost(userId, channelId, conf.T(language, conf.MsgLoadEventsErrorMessage))
+		return
+	}
+	var calendar *dto.Calendar
+	var names []string
+	for _, c := range calendars {
+		userCalendar := dto.Calendar{Path: c.Path, Name: c.Name}
+		names = append(names, "**"+userCalendar.GetDisplayName()+"**")
+		if strings.EqualFold(userCalendar.GetDisplayName(), calendarName) || userCalendar.Path == calendarName {
+			calendar = &userCalendar
+		}
+	}
+	if calendar == nil {
+		hc.sender.SendEphemeralPost(userId, channelId, conf.Tf(language, conf.MsgChannelCalendarNotFound, calendarName, strings.Join(names, ", ")))
+		return
+	}
+	if settings := repository.GetChannelSettings(hc.pluginAPI, channelId); settings != nil && settings.HasCalendar(calendar.Path) {
+		hc.sender.SendEphemeralPost(userId, channelId, conf.Tf(language, conf.MsgChannelCalendarSubscribed, calendar.GetDisplayName()))
+		return
+	}
+	if err := hc.channel.Subscribe(userId, channelId, *calendar); err != nil {
+		hc.pluginAPI.LogWarn("Can't subscribe channel "+channelId+" to calendar", "error", err.Error())
+		hc.sender.SendEphemeralPost(userId, channelId, conf.T(language, conf.MsgSubscribeChannelErrorMessage))
+		return
+	}
+	hc.scheduler.AddChannelCronJobs(channelId)
+	channelLanguage := language
+	if settings := repository.GetChannelSettings(hc.pluginAPI, channelId); settings != nil {
+		channelLanguage = settings.Language
+	}
+	hc.sendChannelSubscriptionChange(userId, channelId, channelLanguage, conf.MsgChannelSubscribedMessage, calendar.GetDisplayName())
+}
+
+func (hc *HookController) unsubscribeChannel(userId string, language string, channelId string, calendarName string) {
+	settings := repository.GetChannelSettings(hc.pluginAPI, channelId)
+	if settings != nil {
+		for _, subscription := range settings.Subscriptions {
+			name := subscription.Calendar.GetDisplayName()
+			if strings.EqualFold(name, calendarName) || subscription.Calendar.Path == calendarName {
+				if hc.channel.Unsubscribe(channelId, subscription.Calendar.Path) {
+					hc.scheduler.DeleteChannelCronJobs(channelId)
+				}
+				hc.sendChannelSubscriptionChange(userId, channelId, settings.Language, conf.MsgChannelUnsubscribedMessage, name)
+				return
+			}
+		}
+	}
+	hc.sender.SendEphemeralPost(userId, channelId, conf.Tf(language, conf.MsgChannelCalendarNotSubscribed, calendarName))
+}
+
+// sendChannelSubscriptionChange tells members of channel who changed its calendars
+func (hc *HookController) sendChannelSubscriptionChange(
+	userId string,
+	channelId string,
+	language string,
+	format conf.MessageId,
+	calendarName string) {
+
+	username := userId
+	if user, appErr := hc.pluginAPI.GetUser(userId); appErr == nil {
+		username = user.Username
+	}
+	hc.sender.SendChannelPost(channelId, conf.Tf(language, format, username, calendarName))
+}
+
+func (hc *HookController) listChannelCalendars(userId string, language string, channelId string) {
+	settings := repository.GetChannelSettings(hc.pluginAPI, channelId)
+	if settings == nil || len(settings.Subscriptions) == 0 {
+		hc.sender.SendEphemeralPost(userId, channelId, conf.T(language, conf.MsgNoChannelSubscriptionsMessage))
+		return
+	}
+	lines := []string{conf.T(language, conf.MsgChannelSubscriptionsTitle)}
+	for _, subscription := range settings.Subscriptions {
+		line := "* **" + subscription.Calendar.GetDisplayName() + "**"
+		if user, appErr := hc.pluginAPI.GetUser(subscription.UserId); appErr == nil {
+			line += " @" + user.Username
+		}
+		lines = append(lines, line)
+	}
+	hc.sender.SendEphemeralPost(userId, channelId, strings.Join(lines, "\n"))
+}
+
+// isChannelAdmin checks user is admin of channel or of the whole system
+func (hc *HookController) isChannelAdmin(channelId string, userId string) bool {
+	if hc.pluginAPI.HasPermissionTo(userId, model.PermissionManageSystem) {
+		return true
+	}
+	member, appErr := hc.pluginAPI.GetChannelMember(channelId, userId)
+	return appErr == nil && member.SchemeAdmin
+}
+
+// getChannelMemberIds returns members of channel if user is member of it too, errors are in language of user
+func (hc *HookController) getChannelMemberIds(teamId string, channelName string, userId string, language string) ([]string, error) {
+	channel, appErr := hc.pluginAPI.GetChannelByName(teamId, channelName, false)
+	if appErr != nil {
+		return nil, errors.New(conf.Tf(language, conf.MsgChannelNotFoundMessage, channelName))
+	}
+	if _, appErr := hc.pluginAPI.GetChannelMember(channel.Id, userId); appErr != nil {
+		return nil, errors.New(conf.Tf(language, conf.MsgNotChannelMemberMessage, channelName))
+	}
+	var memberIds []string
+	for page := 0; ; page++ {
+		members, appErr := hc.pluginAPI.GetChannelMembers(channel.Id, page, channelMembersPerPage)
+		if appErr != nil {
+			return nil, errors.New(conf.Tf(language, conf.MsgChannelMembersErrorMessage, channelName))
+		}
+		for _, member := range members {
+			memberIds = append(memberIds, member.UserId)
+		}
+		if len(members) < channelMembersPerPage {
+			return memberIds, nil
+		}
+	}
+}
+
+// getDayRange returns bounds and title of day in language, today is used if date is absent
+func getDayRange(words []string, location *time.Location, language string) (time.Time, time.Time, string, error) {
+	userNow := time.Now().In(location)
+	expression := strings.Join(words, " ")
+	if expression == "" {
+		expression = "today"
+	}
+	start, err := util.ParseDate(expression, userNow)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	end := time.Date(start.Year(), start.Month(), start.Day(), 23, 59, 59, 0, location)
+	titleName := ""
+	switch {
+	case util.IsSameDay(start, userNow, location):
+		titleName = conf.T(language, conf.MsgTodayEventsTitle)
+	case util.IsSameDay(start, userNow.AddDate(0, 0, 1), location):
+		titleName = conf.T(language, conf.MsgTomorrowEventsTitle)
+	case util.IsSameDay(start, userNow.AddDate(0, 0, -1), location):
+		titleName = conf.T(language, conf.MsgYesterdayEventsTitle)
+	}
+	return start, end, conf.GetEventsTitle(language, titleName, start), nil
+}
+
+// getDaysRange returns bounds of one or two dates, today is used if dates are absent
+func getDaysRange(words []string, location *time.Location) (time.Time, time.Time, error) {
+	if len(words) == 0 {
+		words = []string{"today"}
+	}
+	start, endDay, err := util.ParseDateRange(words, time.Now().In(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), 23, 59, 59, 0, location)
+	return start, end, nil
+}
+
+// isRangeLongerThan checks range from start of the first day to end of the last day has more than days
+func isRangeLongerThan(start time.Time, end time.Time, days int) bool {
+	return !end.Before(start.AddDate(0, 0, days))
+}
+
+// sendDateError shows what is wrong with date and which formats are supported
+func (hc *HookController) sendDateError(userId string, language string, err error) {
+	hc.sender.SendBotDMPost(userId, getErrorMessage(language, err)+". "+conf.T(language, conf.MsgDateFormatsHelp))
 }
 
 func (hc *HookController) help(args *model.CommandArgs) {
-	hc.sender.SendBotDMPost(args.UserId, strings.Replace(CommandHelp, "|", "`", -1))
+	help := conf.T(hc.sender.GetLanguage(args.UserId), conf.MsgCommandHelp)
+	hc.sender.SendBotDMPost(args.UserId, strings.Replace(help, "|", "`", -1))
 }
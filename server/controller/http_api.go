@@ -7,11 +7,14 @@ import (
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/service"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin"
 	"io"
 	"net/http"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -56,6 +59,10 @@ func (hc *HttpController) newRouter() *mux.Router {
 	apiV1.Use(checkAuthenticity)
 
 	apiV1.HandleFunc(conf.CalendarSettings, hc.handleSetupRequest()).Methods(http.MethodPost)
+	apiV1.HandleFunc(conf.CalendarCreateEvent, hc.handleCreateEventRequest()).Methods(http.MethodPost)
+	apiV1.HandleFunc(conf.CalendarRespondEvent, hc.handleRespondEventRequest()).Methods(http.MethodPost)
+	apiV1.HandleFunc(conf.CalendarCreateSlot, hc.handleCreateSlotRequest()).Methods(http.MethodPost)
+	apiV1.HandleFunc(conf.CalendarCompleteTask, hc.handleCompleteTaskRequest()).Methods(http.MethodPost)
 	return router
 }
 
@@ -91,12 +98,25 @@ func (hc *HttpController) handleSetupRequest() http.HandlerFunc {
 			http.Error(w, "not authorized", http.StatusUnauthorized)
 			return
 		}
+		language := hc.sender.GetLanguage(userId)
 
-		settings := &dto.Settings{}
+		calendarNameByPath := make(map[string]string)
+		calendars, _ := hc.calendar.FindCalendars(userId)
+		for _, c := range calendars {
+			calendarNameByPath[c.Path] = c.Name
+		}
+		settings := &dto.Settings{ReminderOffsets: []int{}}
 		for selector, value := range request.Submission {
+			if calendarPath, ok := conf.ParseCalendarDialogOption(selector); ok {
+				if value.(bool) {
+					settings.Calendars = append(settings.Calendars, dto.Calendar{
+						Path: calendarPath,
+						Name: calendarNameByPath[calendarPath],
+					})
+				}
+				continue
+			}
 			switch selector {
-			case conf.SelectCalendarDialogOption:
-				settings.Calendar = value.(string)
 			case conf.SelectTimezoneDialogOption:
 				settings.TimeZone = value.(string)
 			case conf.ChangeStatusOnMeetDialogOption:
@@ -109,23 +129,372 @@ func (hc *HttpController) handleSetupRequest() http.HandlerFunc {
 					dt, _ := time.Parse(time.RFC3339, val)
 					settings.DailyNotifyTime = &dt
 				}
-			case conf.TenMinuteNotifyDialogOption:
-				settings.TenMinutesNotify = value.(bool)
-			case conf.OneMinuteNotifyDialogOption:
-				settings.OneMinutesNotify = value.(bool)
+			case conf.ReminderOffsetsDialogOption:
+				val, _ := value.(string)
+				reminderOffsets, err := util.ParseMinutesList(val)
+				if err != nil {
+					writeDialogFieldErrors(w, map[string]string{
+						conf.ReminderOffsetsDialogOption: getErrorMessage(language, err),
+					})
+					return
+				}
+				settings.ReminderOffsets = reminderOffsets
+			case conf.ShareEventTitlesDialogOption:
+				settings.ShareEventTitles = value.(bool)
+			case conf.ThreadedNotificationsDialogOption:
+				settings.ThreadedNotifications = value.(bool)
+			case conf.EventAlarmsDialogOption:
+				settings.EventAlarms = value.(string)
+			case conf.WorkingHoursDialogOption:
+				val, _ := value.(string)
+				if strings.TrimSpace(val) == "" {
+					continue
+				}
+				weekdays, startMinute, endMinute, err := util.ParseWorkingHours(val)
+				if err != nil {
+					writeDialogFieldErrors(w, map[string]string{
+						conf.WorkingHoursDialogOption: getErrorMessage(language, err),
+					})
+					return
+				}
+				settings.WorkingHours = &dto.WorkingHours{
+					Weekdays:    weekdays,
+					StartMinute: startMinute,
+					EndMinute:   endMinute,
+				}
 			default:
 				hc.pluginAPI.LogWarn("Unknown selector: '" + selector + "' in setup dialog")
 			}
 		}
+		if len(settings.Calendars) == 0 {
+			writeDialogError(w, conf.T(language, conf.MsgNoCalendarSelectedMessage))
+			return
+		}
+		sort.SliceStable(settings.Calendars, func(i, j int) bool {
+			return settings.Calendars[i].Path < settings.Calendars[j].Path
+		})
 		repository.SaveSettings(hc.pluginAPI, userId, *settings)
 
-		events, _ := hc.calendar.LoadCalendar(userId)
-		hc.sender.SendEvents(userId, conf.GetTodayEventsTitle(settings.GetUserNow()), events)
+		events, err := hc.calendar.LoadCalendar(userId)
+		if err != nil {
+			hc.sender.SendBotDMPost(userId, getErrorMessage(language, err))
+		} else {
+			hc.sender.SendEvents(userId, language, conf.GetTodayEventsTitle(language, settings.GetUserNow()), events)
+		}
 		hc.workspace.AddUser(userId)
 		hc.scheduler.AddCronJobs(userId)
 	}
 }
 
+func (hc *HttpController) handleCreateEventRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := submitDialogRequestFromJson(r.Body)
+		if request == nil || request.Submission == nil {
+			hc.pluginAPI.LogWarn("Failed to decode DialogSubmission")
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		userId := request.UserId
+		if userId != r.Header.Get("Mattermost-User-ID") {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		language := hc.sender.GetLanguage(userId)
+		settings := repository.GetSettings(hc.pluginAPI, userId)
+		if settings == nil {
+			writeDialogError(w, conf.T(language, conf.MsgNotConnectedMessage))
+			return
+		}
+
+		submission := make(map[string]string)
+		for selector, value := range request.Submission {
+			if val, ok := value.(string); ok {
+				submission[selector] = strings.TrimSpace(val)
+			}
+		}
+		fieldErrors := make(map[string]string)
+		date, err := util.ParseDate(submission[conf.EventDateDialogOption], time.Now().In(settings.GetUserLocation()))
+		if err != nil {
+			fieldErrors[conf.EventDateDialogOption] = conf.T(language, conf.MsgWrongDialogDateMessage)
+		}
+		startTime, err := time.Parse("15:04", submission[conf.EventStartDialogOption])
+		if err != nil {
+			fieldErrors[conf.EventStartDialogOption] = conf.T(language, conf.MsgStartRequiredMessage)
+		}
+		duration, err := time.ParseDuration(submission[conf.EventDurationDialogOption])
+		if err != nil || duration <= 0 {
+			fieldErrors[conf.EventDurationDialogOption] = conf.T(language, conf.MsgDurationRequiredMessage)
+		}
+		var attendees []dto.Attendee
+		for _, email := range strings.Split(submission[conf.EventAttendeesDialogOption], ",") {
+			email = strings.TrimSpace(email)
+			if email == "" {
+				continue
+			}
+			if !strings.Contains(email, "@") {
+				fieldErrors[conf.EventAttendeesDialogOption] = conf.Tf(language, conf.MsgWrongEmailMessage, email)
+				break
+			}
+			attendees = append(attendees, dto.Attendee{
+				Email:    email,
+				Role:     "REQ-PARTICIPANT",
+				PartStat: dto.PartStatNeedsAction,
+			})
+		}
+		calendarPath := submission[conf.EventCalendarDialogOption]
+		if !settings.HasCalendar(calendarPath) {
+			fieldErrors[conf.EventCalendarDialogOption] = conf.T(language, conf.MsgCalendarRequiredMessage)
+		}
+		if len(fieldErrors) > 0 {
+			writeDialogFieldErrors(w, fieldErrors)
+			return
+		}
+
+		start := time.Date(date.Year(), date.Month(), date.Day(), startTime.Hour(), startTime.Minute(), 0, 0, date.Location())
+		event := dto.NewEvent(
+			model.NewId(),
+			"",
+			submission[conf.EventTitleDialogOption],
+			submission[conf.EventDescriptionDialogOption],
+			"",
+			settings.TimeZone,
+			start,
+			start.Add(duration),
+			false,
+			time.Now().UTC(),
+		)
+		event.Location = submission[conf.EventLocationDialogOption]
+		event.Attendees = attendees
+		event.CalendarPath = calendarPath
+		for _, c := range settings.Calendars {
+			if c.Path == calendarPath {
+				event.CalendarName = c.GetDisplayName()
+			}
+		}
+		if err := hc.calendar.CreateEvent(userId, *event); err != nil {
+			writeDialogError(w, getErrorMessage(language, err))
+			return
+		}
+		hc.sender.SendEvent(userId, language, conf.T(language, conf.MsgCreatedEventTitle), *event)
+	}
+}
+
+func (hc *HttpController) handleRespondEventRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request *model.PostActionIntegrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request == nil {
+			hc.pluginAPI.LogWarn("Failed to decode PostActionIntegrationRequest")
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		userId := request.UserId
+		if userId != r.Header.Get("Mattermost-User-ID") {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		objectPath, _ := request.Context[conf.EventObjectPathActionContext].(string)
+		recurrenceId, _ := request.Context[conf.EventRecurrenceIdActionContext].(string)
+		occurrenceId, _ := request.Context[conf.EventOccurrenceIdActionContext].(string)
+		partStat, _ := request.Context[conf.EventPartStatActionContext].(string)
+		language := hc.sender.GetLanguage(userId)
+		response := getEventResponse(partStat, language)
+		if objectPath == "" || response == "" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if err := hc.calendar.RespondToEvent(userId, objectPath, recurrenceId, partStat); err != nil {
+			writeActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: getErrorMessage(language, err)})
+			return
+		}
+		post, appErr := hc.pluginAPI.GetPost(request.PostId)
+		if appErr != nil {
+			hc.pluginAPI.LogWarn("Failed to get post "+request.PostId, "error", appErr.Error())
+			writeActionResponse(w, &model.PostActionIntegrationResponse{})
+			return
+		}
+		attachments := post.Attachments()
+		for _, attachment := range attachments {
+			if !hasActionWithContext(attachment, conf.EventOccurrenceIdActionContext, occurrenceId) {
+				continue
+			}
+			attachment.Actions = nil
+			attachment.Title = strings.TrimSuffix(attachment.Title, " "+conf.T(language, conf.MsgNeedsActionEventMark))
+			attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{
+				Title: conf.T(language, conf.MsgEventResponseField),
+				Value: response,
+			})
+		}
+		model.ParseSlackAttachment(post, attachments)
+		writeActionResponse(w, &model.PostActionIntegrationResponse{Update: post})
+	}
+}
+
+func (hc *HttpController) handleCreateSlotRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request *model.PostActionIntegrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request == nil {
+			hc.pluginAPI.LogWarn("Failed to decode PostActionIntegrationRequest")
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		userId := request.UserId
+		if userId != r.Header.Get("Mattermost-User-ID") {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		language := hc.sender.GetLanguage(userId)
+		startValue, _ := request.Context[conf.SlotStartActionContext].(string)
+		endValue, _ := request.Context[conf.SlotEndActionContext].(string)
+		participants, _ := request.Context[conf.SlotParticipantsActionContext].(string)
+		start, startErr := time.Parse(time.RFC3339, startValue)
+		end, endErr := time.Parse(time.RFC3339, endValue)
+		participantIds := strings.Split(participants, ",")
+		if startErr != nil || endErr != nil || !containsString(participantIds, userId) {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		slot := dto.TimeSlot{StartTime: start, EndTime: end}
+		if _, err := hc.calendar.CreateMeeting(userId, participantIds, conf.T(language, conf.MsgSlotEventName), slot); err != nil {
+			writeActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: getErrorMessage(language, err)})
+			return
+		}
+		post, appErr := hc.pluginAPI.GetPost(request.PostId)
+		if appErr != nil {
+			hc.pluginAPI.LogWarn("Failed to get post "+request.PostId, "error", appErr.Error())
+			writeActionResponse(w, &model.PostActionIntegrationResponse{})
+			return
+		}
+		attachments := post.Attachments()
+		for _, attachment := range attachments {
+			if !hasActionWithContext(attachment, conf.SlotStartActionContext, startValue) {
+				continue
+			}
+			attachment.Actions = nil
+			attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{
+				Title: conf.T(language, conf.MsgSlotCreatedField),
+				Value: conf.T(language, conf.MsgSlotCreatedValue),
+			})
+		}
+		model.ParseSlackAttachment(post, attachments)
+		writeActionResponse(w, &model.PostActionIntegrationResponse{Update: post})
+	}
+}
+
+func (hc *HttpController) handleCompleteTaskRequest() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request *model.PostActionIntegrationRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request == nil {
+			hc.pluginAPI.LogWarn("Failed to decode PostActionIntegrationRequest")
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		userId := request.UserId
+		if userId != r.Header.Get("Mattermost-User-ID") {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		language := hc.sender.GetLanguage(userId)
+		objectPath, _ := request.Context[conf.EventObjectPathActionContext].(string)
+		taskId, _ := request.Context[conf.TaskIdActionContext].(string)
+		if objectPath == "" || taskId == "" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		if err := hc.calendar.CompleteTask(userId, objectPath, taskId); err != nil {
+			writeActionResponse(w, &model.PostActionIntegrationResponse{EphemeralText: getErrorMessage(language, err)})
+			return
+		}
+		post, appErr := hc.pluginAPI.GetPost(request.PostId)
+		if appErr != nil {
+			hc.pluginAPI.LogWarn("Failed to get post "+request.PostId, "error", appErr.Error())
+			writeActionResponse(w, &model.PostActionIntegrationResponse{})
+			return
+		}
+		attachments := post.Attachments()
+		for _, attachment := range attachments {
+			if !hasActionWithContext(attachment, conf.TaskIdActionContext, taskId) {
+				continue
+			}
+			attachment.Actions = nil
+			attachment.Title = strings.TrimSuffix(attachment.Title, " "+conf.T(language, conf.MsgOverdueTaskMark))
+			attachment.Fields = append(attachment.Fields, &model.SlackAttachmentField{
+				Title: conf.T(language, conf.MsgTaskStatusField),
+				Value: conf.T(language, conf.MsgTaskCompletedValue),
+			})
+		}
+		model.ParseSlackAttachment(post, attachments)
+		writeActionResponse(w, &model.PostActionIntegrationResponse{Update: post})
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func getEventResponse(partStat string, language string) string {
+	switch partStat {
+	case dto.PartStatAccepted:
+		return conf.T(language, conf.MsgAcceptedEventResponse)
+	case dto.PartStatTentative:
+		return conf.T(language, conf.MsgTentativeEventResponse)
+	case dto.PartStatDeclined:
+		return conf.T(language, conf.MsgDeclinedEventResponse)
+	}
+	return ""
+}
+
+// getErrorMessage translates errors of plugin, wrong value of input error is kept as is
+func getErrorMessage(language string, err error) string {
+	switch e := err.(type) {
+	case *util.InputError:
+		return (&util.InputError{Message: conf.T(language, conf.MessageId(e.Message)), Value: e.Value}).Error()
+	case *conf.Error:
+		return conf.T(language, e.Id)
+	}
+	return err.Error()
+}
+
+func hasActionWithContext(attachment *model.SlackAttachment, key string, value string) bool {
+	for _, action := range attachment.Actions {
+		if action.Integration == nil {
+			continue
+		}
+		if action.Integration.Context[key] == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeActionResponse(w http.ResponseWriter, response *model.PostActionIntegrationResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func writeDialogFieldErrors(w http.ResponseWriter, errors map[string]string) {
+	response := &model.SubmitDialogResponse{
+		Errors: errors,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func writeDialogError(w http.ResponseWriter, message string) {
+	response := &model.SubmitDialogResponse{
+		Error: message,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
 func submitDialogRequestFromJson(data io.Reader) *model.SubmitDialogRequest {
 	var o *model.SubmitDialogRequest
 	err := json.NewDecoder(data).Decode(&o)
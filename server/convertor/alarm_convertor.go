@@ -0,0 +1,41 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"strings"
+	"time"
+)
+
+const relatedEnd = "END"
+
+// getAlarms parses TRIGGER of VALARM components, alarms with wrong trigger are skipped
+func getAlarms(e ical.Event, location *time.Location) []dto.Alarm {
+	var alarms []dto.Alarm
+	for _, child := range e.Children {
+		if child.Name != ical.CompAlarm {
+			continue
+		}
+		trigger := child.Props.Get(ical.PropTrigger)
+		if trigger == nil {
+			continue
+		}
+		if trigger.Params.ValueType() == ical.ValueDateTime {
+			alarmTime, err := trigger.DateTime(location)
+			if err != nil {
+				continue
+			}
+			alarms = append(alarms, dto.Alarm{Time: &alarmTime})
+			continue
+		}
+		duration, err := trigger.Duration()
+		if err != nil {
+			continue
+		}
+		alarms = append(alarms, dto.Alarm{
+			Trigger:    duration,
+			RelatedEnd: strings.EqualFold(trigger.Params.Get(ical.ParamRelated), relatedEnd),
+		})
+	}
+	return alarms
+}
@@ -0,0 +1,194 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const mailtoPrefix = "mailto:"
+
+func getOrganizer(e ical.Event) *dto.Attendee {
+	prop := e.Props.Get(ical.PropOrganizer)
+	if prop == nil {
+		return nil
+	}
+	return &dto.Attendee{
+		Email: getCalendarAddressEmail(prop.Value),
+		Name:  prop.Params.Get(ical.ParamCommonName),
+	}
+}
+
+func getAttendees(e ical.Event) []dto.Attendee {
+	var attendees []dto.Attendee
+	for _, prop := range e.Props[ical.PropAttendee] {
+		partStat := strings.ToUpper(prop.Params.Get(ical.ParamParticipationStatus))
+		if partStat == "" {
+			partStat = dto.PartStatNeedsAction
+		}
+		attendees = append(attendees, dto.Attendee{
+			Email:    getCalendarAddressEmail(prop.Value),
+			Name:     prop.Params.Get(ical.ParamCommonName),
+			Role:     strings.ToUpper(prop.Params.Get(ical.ParamRole)),
+			PartStat: partStat,
+		})
+	}
+	return attendees
+}
+
+func getCalendarAddressEmail(address string) string {
+	if strings.HasPrefix(strings.ToLower(address), mailtoPrefix) {
+		return address[len(mailtoPrefix):]
+	}
+	return address
+}
+
+func toCalendarAddressProp(name string, attendee dto.Attendee) *ical.Prop {
+	prop := ical.NewProp(name)
+	prop.Value = mailtoPrefix + attendee.Email
+	if attendee.Name != "" {
+		prop.Params.Set(ical.ParamCommonName, attendee.Name)
+	}
+	if attendee.Role != "" {
+		prop.Params.Set(ical.ParamRole, attendee.Role)
+	}
+	if attendee.PartStat != "" {
+		prop.Params.Set(ical.ParamParticipationStatus, attendee.PartStat)
+	}
+	if attendee.PartStat == dto.PartStatNeedsAction {
+		prop.Params.Set(ical.ParamRSVP, "TRUE")
+	}
+	return prop
+}
+
+// SetAttendeePartStat changes participation status of attendee with one of emails in event occurrence.
+// Override is created if occurrence of recurring event doesn't have it yet.
+// Recurrence id of changed component is returned, it's empty if the whole event was changed
+func SetAttendeePartStat(
+	cal *ical.Calendar,
+	recurrenceId string,
+	emails []string,
+	partStat string,
+	modifiedTime time.Time,
+	location *time.Location) (string, error) {
+
+	resolver := newTimezoneResolver(cal, location)
+	var master *ical.Component
+	var target *ical.Component
+	for _, child := range cal.Children {
+		if child.Name != ical.CompEvent {
+			continue
+		}
+		recurrenceIdProp := child.Props.Get(ical.PropRecurrenceID)
+		if recurrenceIdProp == nil {
+			master = child
+			if recurrenceId == "" {
+				target = child
+			}
+		} else if recurrenceId != "" && getRecurrenceId(recurrenceIdProp, resolver) == recurrenceId {
+			target = child
+		}
+	}
+	if target == nil && master != nil {
+		override, err := newOverride(master, recurrenceId, resolver)
+		if err != nil {
+			return "", err
+		}
+		if override != master {
+			cal.Children = append(cal.Children, override)
+		}
+		target = override
+	}
+	if target == nil {
+		return "", conf.NewError(conf.MsgEventNotFoundError)
+	}
+
+	found := false
+	attendeeProps := target.Props[ical.PropAttendee]
+	for i := range attendeeProps {
+		if !(&dto.Attendee{Email: getCalendarAddressEmail(attendeeProps[i].Value)}).HasEmail(emails) {
+			continue
+		}
+		attendeeProps[i].Params.Set(ical.ParamParticipationStatus, partStat)
+		attendeeProps[i].Params.Del(ical.ParamRSVP)
+		found = true
+	}
+	if !found {
+		return "", conf.NewError(conf.MsgNotAttendeeError)
+	}
+	target.Props.SetDateTime(ical.PropDateTimeStamp, modifiedTime)
+	target.Props.SetDateTime(ical.PropLastModified, modifiedTime)
+	if target == master {
+		return "", nil
+	}
+	return recurrenceId, nil
+}
+
+// newOverride copies master event for occurrence with recurrenceId.
+// Occurrence of all day series is identified by date, because its recurrence id is local midnight
+func newOverride(master *ical.Component, recurrenceId string, resolver *timezoneResolver) (*ical.Component, error) {
+	startProp := master.Props.Get(ical.PropDateTimeStart)
+	if startProp == nil {
+		return master, nil
+	}
+	occurrenceStart, err := util.ParseRecurrenceId(recurrenceId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse recurrence id")
+	}
+	masterEvent := ical.Event{Component: master}
+	masterStart, err := resolver.eventStart(masterEvent)
+	if err != nil {
+		return nil, err
+	}
+	masterEnd, err := resolver.eventEnd(masterEvent, masterStart)
+	if err != nil {
+		return nil, err
+	}
+
+	override := ical.NewComponent(ical.CompEvent)
+	for name, props := range master.Props {
+		if name == ical.PropRecurrenceRule || name == ical.PropRecurrenceDates || name == ical.PropExceptionDates ||
+			name == ical.PropDuration {
+			continue
+		}
+		for _, prop := range props {
+			params := make(ical.Params, len(prop.Params))
+			for key, values := range prop.Params {
+				params[key] = append([]string(nil), values...)
+			}
+			override.Props.Add(&ical.Prop{Name: prop.Name, Params: params, Value: prop.Value})
+		}
+	}
+	override.Children = master.Children
+	if startProp.Params.ValueType() == ical.ValueDate {
+		occurrenceDate := occurrenceStart.In(resolver.defaultLocation)
+		days := int(masterEnd.Sub(masterStart).Hours()/24 + 0.5)
+		setDate(override.Props, ical.PropRecurrenceID, occurrenceDate)
+		setDate(override.Props, ical.PropDateTimeStart, occurrenceDate)
+		setDate(override.Props, ical.PropDateTimeEnd, occurrenceDate.AddDate(0, 0, days))
+		return override, nil
+	}
+	override.Props.SetDateTime(ical.PropRecurrenceID, occurrenceStart)
+	override.Props.SetDateTime(ical.PropDateTimeStart, occurrenceStart)
+	override.Props.SetDateTime(ical.PropDateTimeEnd, occurrenceStart.Add(masterEnd.Sub(masterStart)))
+	return override, nil
+}
+
+func setDate(props ical.Props, name string, date time.Time) {
+	prop := ical.NewProp(name)
+	prop.Params.SetValueType(ical.ValueDate)
+	prop.Value = date.Format(dateFormat)
+	props.Set(prop)
+}
+
+func getRecurrenceId(prop *ical.Prop, resolver *timezoneResolver) string {
+	dt, err := resolver.dateTime(prop)
+	if err != nil {
+		return ""
+	}
+	return util.FormatRecurrenceId(dt)
+}
@@ -0,0 +1,38 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// conferenceUrlRegexp matches links of Yandex Telemost, Zoom, Jitsi, Google Meet and MS Teams meetings
+var conferenceUrlRegexp = regexp.MustCompile(`https?://(?:telemost\.yandex\.(?:ru|com)|(?:[\w-]+\.)?zoom\.us|` +
+	`meet\.jit\.si|meet\.google\.com|teams\.microsoft\.com|teams\.live\.com)/[^\s"'<>()\[\]\\]+`)
+
+// getConferenceUrl searches meeting link in X- properties, LOCATION and DESCRIPTION of event
+func getConferenceUrl(e ical.Event) string {
+	var names []string
+	for name := range e.Props {
+		if strings.HasPrefix(name, "X-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var values []string
+	for _, name := range names {
+		for _, prop := range e.Props[name] {
+			values = append(values, prop.Value)
+		}
+	}
+	location, _ := e.Props.Text(ical.PropLocation)
+	description, _ := e.Props.Text(ical.PropDescription)
+	values = append(values, location, description)
+	for _, value := range values {
+		if url := conferenceUrlRegexp.FindString(value); url != "" {
+			return strings.TrimRight(url, ".,;:!?")
+		}
+	}
+	return ""
+}
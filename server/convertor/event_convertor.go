@@ -9,71 +9,189 @@ import (
 	"time"
 )
 
-func CalendarObjectToEventArray(calendarObjects []caldav.CalendarObject, timezone string) ([]dto.Event, error) {
-	eventById := make(map[string]dto.Event)
+const (
+	productId           = "-//LugaMuga//Mattermost Yandex Calendar Plugin//EN"
+	localDateTimeFormat = "20060102T150405"
+	dateFormat          = "20060102"
+)
+
+// CalendarObjectToEventArray converts calendar objects to events and expands recurring ones
+// into occurrences which intersect [start, end]. Times of events are normalized into location
+func CalendarObjectToEventArray(
+	calendarObjects []caldav.CalendarObject,
+	location *time.Location,
+	start time.Time,
+	end time.Time) ([]dto.Event, error) {
+
+	if location == nil {
+		location = time.UTC
+	}
+	masterById := make(map[string]ical.Event)
+	overridesById := make(map[string][]ical.Event)
+	objectPathById := make(map[string]string)
+	resolverById := make(map[string]*timezoneResolver)
 	for _, calendarObject := range calendarObjects {
+		resolver := newTimezoneResolver(calendarObject.Data, location)
 		for _, e := range calendarObject.Data.Events() {
-			eventId := util.GetPropertyValue(e.Props.Get("UID"))
-			if _, ok := eventById[eventId]; ok {
+			eventId := util.GetPropertyValue(e.Props.Get(ical.PropUID))
+			objectPathById[eventId] = calendarObject.Path
+			resolverById[eventId] = resolver
+			if e.Props.Get(ical.PropRecurrenceID) != nil {
+				overridesById[eventId] = append(overridesById[eventId], e)
 				continue
 			}
-			location, _ := time.LoadLocation(timezone)
-			eventName, _ := e.Props.Text("SUMMARY")
-			eventDescription, _ := e.Props.Text("DESCRIPTION")
-			eventUrl := util.GetPropertyValue(e.Props.Get("URL"))
-
-			startTime, err := e.Props.DateTime("DTSTART", location)
-			if err != nil {
-				return nil, errors.Wrap(err, "Can't parse DTSTART for event "+eventName)
+			if _, ok := masterById[eventId]; ok {
+				continue
 			}
+			masterById[eventId] = e
+		}
+	}
 
-			endTime, err := e.Props.DateTime("DTEND", location)
+	var events []dto.Event
+	overriddenById := make(map[string]map[int64]bool)
+	for eventId, overrides := range overridesById {
+		overriddenById[eventId] = make(map[int64]bool)
+		for _, override := range overrides {
+			recurrenceId, err := resolverById[eventId].dateTime(override.Props.Get(ical.PropRecurrenceID))
 			if err != nil {
-				return nil, errors.Wrap(err, "Can't parse DTEND for event "+eventName)
+				return nil, errors.Wrap(err, "Can't parse RECURRENCE-ID for event "+eventId)
 			}
-
-			lastModifiedTime, err := e.Props.DateTime("LAST-MODIFIED", location)
+			overriddenById[eventId][recurrenceId.Unix()] = true
+			if isCancelled(override) {
+				continue
+			}
+			event, err := toEvent(override, eventId, util.FormatRecurrenceId(recurrenceId), resolverById[eventId])
 			if err != nil {
-				return nil, errors.Wrap(err, "Can't parse LAST-MODIFIED for event "+eventName)
+				return nil, err
 			}
-
-			eventById[eventId] = *dto.NewEvent(
-				eventId,
-				eventName,
-				eventDescription,
-				eventUrl,
-				timezone,
-				startTime,
-				endTime,
-				lastModifiedTime,
-			)
+			event.ObjectPath = objectPathById[eventId]
+			if event.Overlaps(start, end) {
+				events = append(events, *event)
+			}
+		}
+	}
+	for eventId, master := range masterById {
+		if isCancelled(master) {
+			continue
+		}
+		resolver := resolverById[eventId]
+		event, err := toEvent(master, eventId, "", resolver)
+		if err != nil {
+			return nil, err
+		}
+		event.ObjectPath = objectPathById[eventId]
+		occurrences, err := expandOccurrences(master, *event, resolver, start, end, overriddenById[eventId])
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't expand recurrence for event "+event.Name)
 		}
+		events = append(events, occurrences...)
 	}
-	events := make([]dto.Event, 0, len(eventById))
-	for _, event := range eventById {
-		events = append(events, event)
+	for i := range events {
+		events[i].InLocation(location)
 	}
 	return events, nil
 }
 
-func GetTimezone(calendarObjects []caldav.CalendarObject) (string, error) {
-	if len(calendarObjects) == 0 {
-		return "Etc/UTC", nil
+// toEvent converts event with times in its own timezone, so recurrence is expanded correctly across DST
+func toEvent(e ical.Event, eventId string, recurrenceId string, resolver *timezoneResolver) (*dto.Event, error) {
+	eventName, _ := e.Props.Text(ical.PropSummary)
+	eventDescription, _ := e.Props.Text(ical.PropDescription)
+	eventUrl := util.GetPropertyValue(e.Props.Get(ical.PropURL))
+
+	startTime, err := resolver.eventStart(e)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse DTSTART for event "+eventName)
 	}
-	for _, calendarObject := range calendarObjects {
-		for _, child := range calendarObject.Data.Children {
-			if child.Name == ical.CompTimezone {
-				return child.Props.Text("TZID")
-			}
+
+	endTime, err := resolver.eventEnd(e, startTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse DTEND for event "+eventName)
+	}
+
+	startProp := e.Props.Get(ical.PropDateTimeStart)
+	allDay := startProp != nil && startProp.Params.ValueType() == ical.ValueDate
+
+	lastModifiedTime, err := e.Props.DateTime(ical.PropLastModified, time.UTC)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse LAST-MODIFIED for event "+eventName)
+	}
+
+	event := dto.NewEvent(
+		eventId,
+		recurrenceId,
+		eventName,
+		eventDescription,
+		eventUrl,
+		startTime.Location().String(),
+		startTime,
+		endTime,
+		allDay,
+		lastModifiedTime,
+	)
+	event.Location, _ = e.Props.Text(ical.PropLocation)
+	event.ConferenceUrl = getConferenceUrl(e)
+	event.Alarms = getAlarms(e, resolver.defaultLocation)
+	event.Organizer = getOrganizer(e)
+	event.Attendees = getAttendees(e)
+	return event, nil
+}
+
+// EventToCalendar builds calendar object with single event for saving on server
+func EventToCalendar(event dto.Event) *ical.Calendar {
+	e := ical.NewEvent()
+	e.Props.SetText(ical.PropUID, event.Id)
+	e.Props.SetDateTime(ical.PropDateTimeStamp, time.Now())
+	setDateTimeInLocation(e.Props, ical.PropDateTimeStart, event.StartTime)
+	setDateTimeInLocation(e.Props, ical.PropDateTimeEnd, event.EndTime)
+	e.Props.SetText(ical.PropSummary, event.Name)
+	if event.Description != "" {
+		e.Props.SetText(ical.PropDescription, event.Description)
+	}
+	if event.Location != "" {
+		e.Props.SetText(ical.PropLocation, event.Location)
+	}
+	if event.Organizer != nil {
+		e.Props.Set(toCalendarAddressProp(ical.PropOrganizer, *event.Organizer))
+	}
+	for _, attendee := range event.Attendees {
+		e.Props.Add(toCalendarAddressProp(ical.PropAttendee, attendee))
+	}
+
+	cal := ical.NewCalendar()
+	cal.Props.SetText(ical.PropVersion, "2.0")
+	cal.Props.SetText(ical.PropProductID, productId)
+	timezoneIds := make(map[string]bool)
+	for _, dt := range []time.Time{event.StartTime, event.EndTime} {
+		if location := dt.Location(); location != time.UTC && !timezoneIds[location.String()] {
+			timezoneIds[location.String()] = true
+			cal.Children = append(cal.Children, locationToTimezone(location, event.StartTime, event.EndTime))
 		}
 	}
-	return "Etc/UTC", errors.New("Timezone not found")
+	cal.Children = append(cal.Children, e.Component)
+	return cal
+}
+
+// setDateTimeInLocation keeps local time with TZID defined by VTIMEZONE of calendar, so DST is handled in event timezone
+func setDateTimeInLocation(props ical.Props, name string, dt time.Time) {
+	if dt.Location() == time.UTC {
+		props.SetDateTime(name, dt)
+		return
+	}
+	prop := ical.NewProp(name)
+	prop.Params.Set(ical.ParamTimezoneID, dt.Location().String())
+	prop.Value = dt.Format(localDateTimeFormat)
+	props.Set(prop)
+}
+
+func isCancelled(e ical.Event) bool {
+	status, _ := e.Status()
+	return status == ical.EventCancelled
 }
 
-func SliceEventToMapById(events []dto.Event) map[string]dto.Event {
+func SliceEventToMapByOccurrenceId(events []dto.Event) map[string]dto.Event {
 	eventsById := make(map[string]dto.Event, len(events))
 	for _, e := range events {
-		eventsById[e.Id] = e
+		eventsById[e.GetOccurrenceId()] = e
 	}
 	return eventsById
 }
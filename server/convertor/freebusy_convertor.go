@@ -0,0 +1,60 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const freeBusyTypeFree = "FREE"
+
+// FreeBusyToIntervals reads busy periods of VFREEBUSY components from free-busy-query response
+func FreeBusyToIntervals(cal *ical.Calendar) ([]dto.BusyInterval, error) {
+	var intervals []dto.BusyInterval
+	for _, child := range cal.Children {
+		if child.Name != ical.CompFreeBusy {
+			continue
+		}
+		for _, prop := range child.Props[ical.PropFreeBusy] {
+			if strings.EqualFold(prop.Params.Get(ical.ParamFreeBusyType), freeBusyTypeFree) {
+				continue
+			}
+			for _, period := range strings.Split(prop.Value, ",") {
+				interval, err := parsePeriod(strings.TrimSpace(period))
+				if err != nil {
+					return nil, errors.Wrap(err, "Can't parse FREEBUSY period "+period)
+				}
+				intervals = append(intervals, interval)
+			}
+		}
+	}
+	return intervals, nil
+}
+
+// parsePeriod parses period with explicit end or with duration, e.g. 19970308T160000Z/PT8H30M
+func parsePeriod(period string) (dto.BusyInterval, error) {
+	parts := strings.SplitN(period, "/", 2)
+	if len(parts) != 2 {
+		return dto.BusyInterval{}, errors.New("Period should have start and end")
+	}
+	start, err := (&ical.Prop{Name: ical.PropDateTimeStart, Params: ical.Params{}, Value: parts[0]}).DateTime(time.UTC)
+	if err != nil {
+		return dto.BusyInterval{}, err
+	}
+	var end time.Time
+	if strings.HasPrefix(parts[1], "P") || strings.HasPrefix(parts[1], "+P") {
+		duration, err := (&ical.Prop{Name: ical.PropDuration, Params: ical.Params{}, Value: parts[1]}).Duration()
+		if err != nil {
+			return dto.BusyInterval{}, err
+		}
+		end = start.Add(duration)
+	} else {
+		end, err = (&ical.Prop{Name: ical.PropDateTimeEnd, Params: ical.Params{}, Value: parts[1]}).DateTime(time.UTC)
+		if err != nil {
+			return dto.BusyInterval{}, err
+		}
+	}
+	return dto.BusyInterval{StartTime: start, EndTime: end}, nil
+}
@@ -0,0 +1,116 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/pkg/errors"
+	"github.com/teambition/rrule-go"
+	"strings"
+	"time"
+)
+
+// expandOccurrences returns occurrences of master event which intersect [start, end].
+// Occurrences replaced by RECURRENCE-ID overrides are skipped
+func expandOccurrences(
+	master ical.Event,
+	event dto.Event,
+	resolver *timezoneResolver,
+	start time.Time,
+	end time.Time,
+	overridden map[int64]bool) ([]dto.Event, error) {
+
+	var occurrences []dto.Event
+	rruleProp := master.Props.Get(ical.PropRecurrenceRule)
+	if rruleProp == nil && master.Props.Get(ical.PropRecurrenceDates) == nil {
+		if event.Overlaps(start, end) {
+			occurrences = append(occurrences, event)
+		}
+		return occurrences, nil
+	}
+
+	// Rule is applied in event timezone, so occurrences keep local time after DST change
+	location := event.StartTime.Location()
+	// Location of VTIMEZONE has fixed offset, so rule is applied to local time and offset is set per occurrence
+	timezone := resolver.getTimezone(location)
+	toLocation := func(dt time.Time) time.Time {
+		return dt
+	}
+	if timezone != nil {
+		toLocation = func(dt time.Time) time.Time {
+			return toWallClock(dt, location, timezone)
+		}
+	}
+	set := rrule.Set{}
+	set.DTStart(event.StartTime)
+	if rruleProp != nil {
+		option, err := rrule.StrToROptionInLocation(rruleProp.Value, location)
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't parse RRULE")
+		}
+		option.Dtstart = event.StartTime
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't build RRULE")
+		}
+		set.RRule(rule)
+	}
+	// DTSTART is always the first occurrence even if it doesn't match RRULE
+	set.RDate(event.StartTime)
+	rDates, err := getDateTimeList(master.Props, ical.PropRecurrenceDates, resolver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse RDATE")
+	}
+	for _, rDate := range rDates {
+		set.RDate(toLocation(rDate))
+	}
+	exDates, err := getDateTimeList(master.Props, ical.PropExceptionDates, resolver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse EXDATE")
+	}
+	for _, exDate := range exDates {
+		set.ExDate(toLocation(exDate))
+	}
+
+	duration := event.EndTime.Sub(event.StartTime)
+	// Range is widened by a day as local time of VTIMEZONE occurrences differs from their instant by DST shift
+	for _, occurrenceStart := range set.Between(start.Add(-duration).AddDate(0, 0, -1), end.AddDate(0, 0, 1), true) {
+		if timezone != nil {
+			if dt, ok := inTimezone(occurrenceStart, location.String(), timezone); ok {
+				occurrenceStart = dt
+			}
+		}
+		if overridden[occurrenceStart.Unix()] {
+			continue
+		}
+		occurrence := event.NewOccurrence(
+			util.FormatRecurrenceId(occurrenceStart),
+			occurrenceStart,
+			occurrenceStart.Add(duration),
+		)
+		if occurrence.Overlaps(start, end) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences, nil
+}
+
+// getDateTimeList parses all values of multi-valued date properties like RDATE and EXDATE
+func getDateTimeList(props ical.Props, name string, resolver *timezoneResolver) ([]time.Time, error) {
+	var dateTimes []time.Time
+	for _, prop := range props[name] {
+		if prop.Params.ValueType() == ical.ValuePeriod {
+			continue
+		}
+		for _, value := range strings.Split(prop.Value, ",") {
+			valueProp := prop
+			valueProp.Value = strings.TrimSpace(value)
+			dateTime, err := resolver.dateTime(&valueProp)
+			if err != nil {
+				return nil, err
+			}
+			dateTimes = append(dateTimes, dateTime)
+		}
+	}
+	return dateTimes, nil
+}
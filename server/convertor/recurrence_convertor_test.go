@@ -0,0 +1,213 @@
+package convertor
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/go-webdav/caldav"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+)
+
+const overriddenOccurrenceIcs = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:standup
+DTSTART;TZID=Europe/Moscow:20260309T100000
+DTEND;TZID=Europe/Moscow:20260309T103000
+RRULE:FREQ=DAILY;COUNT=3
+SUMMARY:Standup
+LAST-MODIFIED:20260301T000000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Moscow:20260310T100000
+DTSTART;TZID=Europe/Moscow:20260310T120000
+DTEND;TZID=Europe/Moscow:20260310T123000
+SUMMARY:Moved standup
+LAST-MODIFIED:20260302T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+const excludedOccurrenceIcs = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:standup
+DTSTART;TZID=Europe/Moscow:20260309T100000
+DTEND;TZID=Europe/Moscow:20260309T103000
+RRULE:FREQ=DAILY;COUNT=4
+EXDATE;TZID=Europe/Moscow:20260310T100000
+EXDATE:20260311T070000Z
+SUMMARY:Standup
+LAST-MODIFIED:20260301T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+const weeklyOverDstIcs = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:weekly
+DTSTART;TZID=Europe/Berlin:20260319T090000
+DTEND;TZID=Europe/Berlin:20260319T100000
+RRULE:FREQ=WEEKLY;COUNT=3
+SUMMARY:Weekly
+LAST-MODIFIED:20260301T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+const allDaySeriesIcs = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:vacation
+DTSTART;VALUE=DATE:20260309
+DTEND;VALUE=DATE:20260310
+RRULE:FREQ=DAILY;COUNT=3
+SUMMARY:Vacation
+LAST-MODIFIED:20260301T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestExpandOccurrences(t *testing.T) {
+	moscow := loadTestLocation(t, "Europe/Moscow")
+	berlin := loadTestLocation(t, "Europe/Berlin")
+	tests := []struct {
+		name       string
+		ics        string
+		location   *time.Location
+		wantStarts []time.Time
+		wantNames  []string
+		wantLength time.Duration
+		wantAllDay bool
+	}{
+		{
+			name:     "overridden occurrence",
+			ics:      overriddenOccurrenceIcs,
+			location: moscow,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 9, 10, 0, 0, 0, moscow),
+				time.Date(2026, 3, 10, 12, 0, 0, 0, moscow),
+				time.Date(2026, 3, 11, 10, 0, 0, 0, moscow),
+			},
+			wantNames:  []string{"Standup", "Moved standup", "Standup"},
+			wantLength: 30 * time.Minute,
+		},
+		{
+			name:     "cancelled occurrences",
+			ics:      excludedOccurrenceIcs,
+			location: moscow,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 9, 10, 0, 0, 0, moscow),
+				time.Date(2026, 3, 12, 10, 0, 0, 0, moscow),
+			},
+			wantLength: 30 * time.Minute,
+		},
+		{
+			// Berlin moves to summer time on 29.03, local time of occurrences is kept
+			name:     "weekly over DST change",
+			ics:      weeklyOverDstIcs,
+			location: time.UTC,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 19, 9, 0, 0, 0, berlin),
+				time.Date(2026, 3, 26, 9, 0, 0, 0, berlin),
+				time.Date(2026, 4, 2, 9, 0, 0, 0, berlin),
+			},
+			wantLength: time.Hour,
+		},
+		{
+			name:     "all day series",
+			ics:      allDaySeriesIcs,
+			location: moscow,
+			wantStarts: []time.Time{
+				time.Date(2026, 3, 9, 0, 0, 0, 0, moscow),
+				time.Date(2026, 3, 10, 0, 0, 0, 0, moscow),
+				time.Date(2026, 3, 11, 0, 0, 0, 0, moscow),
+			},
+			wantLength: 24 * time.Hour,
+			wantAllDay: true,
+		},
+	}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := convertTestCalendar(t, tt.ics, tt.location, start, end)
+			if len(events) != len(tt.wantStarts) {
+				t.Fatalf("got %d occurrences, want %d", len(events), len(tt.wantStarts))
+			}
+			occurrenceIds := make(map[string]bool)
+			for i, event := range events {
+				if !event.StartTime.Equal(tt.wantStarts[i]) {
+					t.Errorf("occurrence %d starts at %v, want %v", i, event.StartTime, tt.wantStarts[i])
+				}
+				if event.StartTime.Location() != tt.location {
+					t.Errorf("occurrence %d is in %v, want %v", i, event.StartTime.Location(), tt.location)
+				}
+				if length := event.EndTime.Sub(event.StartTime); length != tt.wantLength {
+					t.Errorf("occurrence %d lasts %v, want %v", i, length, tt.wantLength)
+				}
+				if event.AllDay != tt.wantAllDay {
+					t.Errorf("occurrence %d has all day %v, want %v", i, event.AllDay, tt.wantAllDay)
+				}
+				if tt.wantNames != nil && event.Name != tt.wantNames[i] {
+					t.Errorf("occurrence %d is named %q, want %q", i, event.Name, tt.wantNames[i])
+				}
+				occurrenceIds[event.GetOccurrenceId()] = true
+			}
+			if len(occurrenceIds) != len(events) {
+				t.Errorf("occurrence ids aren't unique: %v", occurrenceIds)
+			}
+		})
+	}
+}
+
+func TestExpandOccurrencesKeepsRecurrenceIdOfOverride(t *testing.T) {
+	moscow := loadTestLocation(t, "Europe/Moscow")
+	start := time.Date(2026, 3, 10, 0, 0, 0, 0, moscow)
+	end := time.Date(2026, 3, 10, 23, 59, 59, 0, moscow)
+	events := convertTestCalendar(t, overriddenOccurrenceIcs, moscow, start, end)
+	if len(events) != 1 {
+		t.Fatalf("got %d occurrences, want 1", len(events))
+	}
+	wantRecurrenceId := util.FormatRecurrenceId(time.Date(2026, 3, 10, 10, 0, 0, 0, moscow))
+	if events[0].RecurrenceId != wantRecurrenceId {
+		t.Errorf("got recurrence id %q, want %q of replaced occurrence", events[0].RecurrenceId, wantRecurrenceId)
+	}
+}
+
+// convertTestCalendar decodes calendar object and returns its events sorted by start
+func convertTestCalendar(t *testing.T, ics string, location *time.Location, start time.Time, end time.Time) []dto.Event {
+	t.Helper()
+	cal, err := ical.NewDecoder(strings.NewReader(strings.ReplaceAll(ics, "\n", "\r\n"))).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := []caldav.CalendarObject{{Path: "/calendars/test/event.ics", Data: cal}}
+	events, err := CalendarObjectToEventArray(objects, location, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].StartTime.Before(events[j].StartTime)
+	})
+	return events
+}
+
+func loadTestLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
@@ -0,0 +1,93 @@
+package convertor
+
+import (
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/go-webdav/caldav"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// CalendarObjectToTaskArray converts open tasks with due date, due times are normalized into location.
+// Recurring tasks aren't expanded, overrides of their occurrences are skipped
+func CalendarObjectToTaskArray(calendarObjects []caldav.CalendarObject, location *time.Location) ([]dto.Task, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	var tasks []dto.Task
+	for _, calendarObject := range calendarObjects {
+		resolver := newTimezoneResolver(calendarObject.Data, location)
+		for _, child := range calendarObject.Data.Children {
+			if child.Name != ical.CompToDo || child.Props.Get(ical.PropRecurrenceID) != nil {
+				continue
+			}
+			task, err := toTask(child, resolver)
+			if err != nil {
+				return nil, err
+			}
+			if task == nil || !task.IsOpen() {
+				continue
+			}
+			task.ObjectPath = calendarObject.Path
+			task.InLocation(location)
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+func toTask(todo *ical.Component, resolver *timezoneResolver) (*dto.Task, error) {
+	dueProp := todo.Props.Get(ical.PropDue)
+	if dueProp == nil {
+		return nil, nil
+	}
+	taskName, _ := todo.Props.Text(ical.PropSummary)
+	dueTime, err := resolver.dateTime(dueProp)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse DUE for task "+taskName)
+	}
+	lastModifiedTime, err := todo.Props.DateTime(ical.PropLastModified, time.UTC)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse LAST-MODIFIED for task "+taskName)
+	}
+	taskDescription, _ := todo.Props.Text(ical.PropDescription)
+	taskStatus, _ := todo.Props.Text(ical.PropStatus)
+	return &dto.Task{
+		Id:               util.GetPropertyValue(todo.Props.Get(ical.PropUID)),
+		Name:             taskName,
+		Description:      taskDescription,
+		Url:              util.GetPropertyValue(todo.Props.Get(ical.PropURL)),
+		DueTime:          dueTime,
+		AllDay:           dueProp.Params.ValueType() == ical.ValueDate,
+		Status:           taskStatus,
+		LastModifiedTime: lastModifiedTime,
+	}, nil
+}
+
+// SetTaskCompleted marks task with taskId as completed at modifiedTime
+func SetTaskCompleted(cal *ical.Calendar, taskId string, modifiedTime time.Time) error {
+	found := false
+	for _, child := range cal.Children {
+		if child.Name != ical.CompToDo || util.GetPropertyValue(child.Props.Get(ical.PropUID)) != taskId {
+			continue
+		}
+		setRawValue(child.Props, ical.PropStatus, dto.TaskStatusCompleted)
+		setRawValue(child.Props, ical.PropPercentComplete, "100")
+		child.Props.SetDateTime(ical.PropCompleted, modifiedTime)
+		child.Props.SetDateTime(ical.PropDateTimeStamp, modifiedTime)
+		child.Props.SetDateTime(ical.PropLastModified, modifiedTime)
+		found = true
+	}
+	if !found {
+		return errors.New("Task not found in calendar object")
+	}
+	return nil
+}
+
+// setRawValue sets property without VALUE parameter, it's needed for non-text properties like PERCENT-COMPLETE
+func setRawValue(props ical.Props, name string, value string) {
+	prop := ical.NewProp(name)
+	prop.Value = value
+	props.Set(prop)
+}
@@ -0,0 +1,327 @@
+package convertor
+
+import (
+	"fmt"
+	"github.com/emersion/go-ical"
+	"github.com/teambition/rrule-go"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	compStandard     = "STANDARD"
+	compDaylight     = "DAYLIGHT"
+	propTzOffsetFrom = "TZOFFSETFROM"
+	propTzOffsetTo   = "TZOFFSETTO"
+	propTzName       = "TZNAME"
+)
+
+// timezoneResolver parses date-time properties in location of their TZID.
+// Unknown TZID is resolved by Windows zone name or by VTIMEZONE embedded into calendar object
+type timezoneResolver struct {
+	defaultLocation *time.Location
+	timezones       map[string]*ical.Component
+}
+
+// newTimezoneResolver collects VTIMEZONE of calendar, floating times and dates are parsed in defaultLocation
+func newTimezoneResolver(cal *ical.Calendar, defaultLocation *time.Location) *timezoneResolver {
+	resolver := &timezoneResolver{
+		defaultLocation: defaultLocation,
+		timezones:       make(map[string]*ical.Component),
+	}
+	if cal == nil {
+		return resolver
+	}
+	for _, child := range cal.Children {
+		if child.Name != ical.CompTimezone {
+			continue
+		}
+		tzid, _ := child.Props.Text(ical.PropTimezoneID)
+		resolver.timezones[tzid] = child
+	}
+	return resolver
+}
+
+func (r *timezoneResolver) dateTime(prop *ical.Prop) (time.Time, error) {
+	tzid := prop.Params.Get(ical.ParamTimezoneID)
+	if prop.Params.ValueType() == ical.ValueDate || tzid == "" || strings.HasSuffix(prop.Value, "Z") {
+		return prop.DateTime(r.defaultLocation)
+	}
+	if location := loadLocation(tzid); location != nil {
+		return prop.DateTime(location)
+	}
+	wallClock, err := prop.DateTime(time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if timezone, ok := r.timezones[tzid]; ok {
+		if dt, ok := inTimezone(wallClock, tzid, timezone); ok {
+			return dt, nil
+		}
+	}
+	return prop.DateTime(r.defaultLocation)
+}
+
+// getTimezone returns VTIMEZONE which location was built from, it's nil for locations from tzdb.
+// Such location has only offset of one date, so offsets of other dates are found by the VTIMEZONE
+func (r *timezoneResolver) getTimezone(location *time.Location) *ical.Component {
+	tzid := location.String()
+	if _, ok := r.timezones[tzid]; !ok || loadLocation(tzid) != nil {
+		return nil
+	}
+	return r.timezones[tzid]
+}
+
+// inTimezone sets offset of VTIMEZONE in effect at local time of wallClock
+func inTimezone(wallClock time.Time, tzid string, timezone *ical.Component) (time.Time, bool) {
+	wallClock = time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(),
+		wallClock.Minute(), wallClock.Second(), 0, time.UTC)
+	offset, ok := getTimezoneOffset(timezone, wallClock)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(),
+		wallClock.Minute(), wallClock.Second(), 0, time.FixedZone(tzid, offset)), true
+}
+
+// toWallClock keeps local time of dt in VTIMEZONE location with fixed offset, so recurrence rules are applied
+// to local time. Time in other timezone is moved to local time by VTIMEZONE offset at its date
+func toWallClock(dt time.Time, location *time.Location, timezone *ical.Component) time.Time {
+	if dt.Location().String() != location.String() {
+		utc := dt.UTC()
+		_, offset := dt.In(location).Zone()
+		if actualOffset, ok := getTimezoneOffset(timezone, utc.Add(time.Duration(offset)*time.Second)); ok {
+			offset = actualOffset
+		}
+		dt = utc.Add(time.Duration(offset) * time.Second)
+	}
+	return time.Date(dt.Year(), dt.Month(), dt.Day(), dt.Hour(), dt.Minute(), dt.Second(), 0, location)
+}
+
+// eventStart parses DTSTART of event in its timezone
+func (r *timezoneResolver) eventStart(e ical.Event) (time.Time, error) {
+	prop := e.Props.Get(ical.PropDateTimeStart)
+	if prop == nil {
+		return time.Time{}, nil
+	}
+	return r.dateTime(prop)
+}
+
+// eventEnd parses DTEND or calculates end by DURATION, all day event without both lasts one day
+func (r *timezoneResolver) eventEnd(e ical.Event, start time.Time) (time.Time, error) {
+	if prop := e.Props.Get(ical.PropDateTimeEnd); prop != nil {
+		return r.dateTime(prop)
+	}
+	if prop := e.Props.Get(ical.PropDuration); prop != nil {
+		duration, err := prop.Duration()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return start.Add(duration), nil
+	}
+	startProp := e.Props.Get(ical.PropDateTimeStart)
+	if startProp != nil && startProp.Params.ValueType() == ical.ValueDate {
+		return start.AddDate(0, 0, 1), nil
+	}
+	return start, nil
+}
+
+// loadLocation finds location in tzdb by IANA name, Windows name or IANA suffix of custom TZID
+// like /mozilla.org/20050126_1/Europe/Moscow
+func loadLocation(tzid string) *time.Location {
+	tzid = strings.Trim(tzid, "\"")
+	if location, err := time.LoadLocation(tzid); err == nil && tzid != "" && tzid != "Local" {
+		return location
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		if location, err := time.LoadLocation(name); err == nil {
+			return location
+		}
+	}
+	parts := strings.Split(strings.Trim(tzid, "/"), "/")
+	for i := 1; i < len(parts); i++ {
+		if location, err := time.LoadLocation(strings.Join(parts[i:], "/")); err == nil {
+			return location
+		}
+	}
+	return nil
+}
+
+// getTimezoneOffset finds offset of VTIMEZONE at local time by the last STANDARD or DAYLIGHT onset before it
+func getTimezoneOffset(timezone *ical.Component, wallClock time.Time) (int, bool) {
+	var lastOnset time.Time
+	lastOffset, found := 0, false
+	var firstOnset time.Time
+	firstOffset := 0
+	for _, observance := range timezone.Children {
+		if observance.Name != compStandard && observance.Name != compDaylight {
+			continue
+		}
+		offsetTo, err := parseUtcOffset(observance.Props.Get(propTzOffsetTo))
+		if err != nil {
+			continue
+		}
+		offsetFrom, err := parseUtcOffset(observance.Props.Get(propTzOffsetFrom))
+		if err != nil {
+			offsetFrom = offsetTo
+		}
+		for _, onset := range getObservanceOnsets(observance, wallClock) {
+			if !onset.After(wallClock) && (!found || onset.After(lastOnset)) {
+				lastOnset, lastOffset, found = onset, offsetTo, true
+			}
+			if firstOnset.IsZero() || onset.Before(firstOnset) {
+				firstOnset, firstOffset = onset, offsetFrom
+			}
+		}
+	}
+	if found {
+		return lastOffset, true
+	}
+	return firstOffset, !firstOnset.IsZero()
+}
+
+// getObservanceOnsets returns DTSTART, RDATE and the last RRULE onset before wallClock, all in local time as UTC
+func getObservanceOnsets(observance *ical.Component, wallClock time.Time) []time.Time {
+	startProp := observance.Props.Get(ical.PropDateTimeStart)
+	if startProp == nil {
+		return nil
+	}
+	start, err := startProp.DateTime(time.UTC)
+	if err != nil {
+		return nil
+	}
+	onsets := []time.Time{start}
+	rDates, err := getDateTimeList(observance.Props, ical.PropRecurrenceDates, newTimezoneResolver(nil, time.UTC))
+	if err == nil {
+		onsets = append(onsets, rDates...)
+	}
+	if rruleProp := observance.Props.Get(ical.PropRecurrenceRule); rruleProp != nil {
+		option, err := rrule.StrToROptionInLocation(rruleProp.Value, time.UTC)
+		if err != nil {
+			return onsets
+		}
+		option.Dtstart = start
+		rule, err := rrule.NewRRule(*option)
+		if err != nil {
+			return onsets
+		}
+		if onset := rule.Before(wallClock, true); !onset.IsZero() {
+			onsets = append(onsets, onset)
+		}
+	}
+	return onsets
+}
+
+// parseUtcOffset parses offset like +0300 or -043000 to seconds
+func parseUtcOffset(prop *ical.Prop) (int, error) {
+	if prop == nil {
+		return 0, strconv.ErrSyntax
+	}
+	value := strings.TrimSpace(prop.Value)
+	if len(value) != 5 && len(value) != 7 {
+		return 0, strconv.ErrSyntax
+	}
+	sign := 1
+	switch value[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, strconv.ErrSyntax
+	}
+	hours, err := strconv.Atoi(value[1:3])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(value[3:5])
+	if err != nil {
+		return 0, err
+	}
+	seconds := 0
+	if len(value) == 7 {
+		if seconds, err = strconv.Atoi(value[5:7]); err != nil {
+			return 0, err
+		}
+	}
+	return sign * (hours*3600 + minutes*60 + seconds), nil
+}
+
+// locationToTimezone builds VTIMEZONE of location with its offset transitions a year around [start, end],
+// so TZID of event is defined inside of calendar object as RFC 5545 requires
+func locationToTimezone(location *time.Location, start time.Time, end time.Time) *ical.Component {
+	timezone := ical.NewComponent(ical.CompTimezone)
+	setPropValue(timezone.Props, ical.PropTimezoneID, location.String())
+	from := start.AddDate(-1, 0, 0).In(location)
+	to := end.AddDate(1, 0, 0).In(location)
+	// the smallest offset is standard time, larger ones are daylight saving time
+	_, standardOffset := from.Zone()
+	transitions := []time.Time{from}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		offset := getOffset(next)
+		if offset != getOffset(day) {
+			transitions = append(transitions, findTransition(day, next))
+		}
+		if offset < standardOffset {
+			standardOffset = offset
+		}
+	}
+	for i, transition := range transitions {
+		name, offsetTo := transition.Zone()
+		offsetFrom := offsetTo
+		if i > 0 {
+			offsetFrom = getOffset(transition.Add(-time.Second))
+		}
+		observance := ical.NewComponent(compStandard)
+		if offsetTo > standardOffset {
+			observance.Name = compDaylight
+		}
+		// onset is in local time of previous offset
+		onset := transition.UTC().Add(time.Duration(offsetFrom) * time.Second)
+		setPropValue(observance.Props, ical.PropDateTimeStart, onset.Format(localDateTimeFormat))
+		setPropValue(observance.Props, propTzOffsetFrom, formatUtcOffset(offsetFrom))
+		setPropValue(observance.Props, propTzOffsetTo, formatUtcOffset(offsetTo))
+		setPropValue(observance.Props, propTzName, name)
+		timezone.Children = append(timezone.Children, observance)
+	}
+	return timezone
+}
+
+// setPropValue sets property without VALUE parameter, which isn't allowed for VTIMEZONE properties
+func setPropValue(props ical.Props, name string, value string) {
+	prop := ical.NewProp(name)
+	prop.Value = value
+	props.Set(prop)
+}
+
+func getOffset(t time.Time) int {
+	_, offset := t.Zone()
+	return offset
+}
+
+// findTransition finds the first second with offset of end by binary search
+func findTransition(start time.Time, end time.Time) time.Time {
+	offset := getOffset(end)
+	for end.Sub(start) > time.Second {
+		middle := start.Add(end.Sub(start) / 2).Truncate(time.Second)
+		if getOffset(middle) == offset {
+			end = middle
+		} else {
+			start = middle
+		}
+	}
+	return end
+}
+
+// formatUtcOffset formats offset in seconds like +0300, seconds are added only if they aren't zero
+func formatUtcOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	if offset%60 != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, offset/3600, offset/60%60, offset%60)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset/60%60)
+}
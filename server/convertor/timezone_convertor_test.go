@@ -0,0 +1,163 @@
+package convertor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-ical"
+)
+
+// customTimezoneIcs has VTIMEZONE which isn't in tzdb, it follows rules of Central Europe
+const customTimezoneIcs = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VTIMEZONE
+TZID:Custom Central Time
+BEGIN:STANDARD
+DTSTART:19701025T030000
+RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+TZNAME:CCT
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:19700329T020000
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+TZNAME:CCST
+END:DAYLIGHT
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:weekly
+DTSTART;TZID=Custom Central Time:20260319T090000
+DTEND;TZID=Custom Central Time:20260319T100000
+RRULE:FREQ=WEEKLY;COUNT=3
+SUMMARY:Weekly
+LAST-MODIFIED:20260301T000000Z
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestTimezoneResolverDateTime(t *testing.T) {
+	moscow := loadTestLocation(t, "Europe/Moscow")
+	cal, err := ical.NewDecoder(strings.NewReader(strings.ReplaceAll(customTimezoneIcs, "\n", "\r\n"))).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := newTimezoneResolver(cal, time.UTC)
+	tests := []struct {
+		name  string
+		tzid  string
+		value string
+		want  time.Time
+	}{
+		{
+			name:  "custom timezone in winter",
+			tzid:  "Custom Central Time",
+			value: "20260120T090000",
+			want:  time.Date(2026, 1, 20, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "custom timezone in summer",
+			tzid:  "Custom Central Time",
+			value: "20260701T090000",
+			want:  time.Date(2026, 7, 1, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "custom timezone after fall back",
+			tzid:  "Custom Central Time",
+			value: "20261025T040000",
+			want:  time.Date(2026, 10, 25, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "windows zone name",
+			tzid:  "Russian Standard Time",
+			value: "20260310T090000",
+			want:  time.Date(2026, 3, 10, 9, 0, 0, 0, moscow),
+		},
+		{
+			name:  "quoted windows zone name",
+			tzid:  "\"Russian Standard Time\"",
+			value: "20260310T090000",
+			want:  time.Date(2026, 3, 10, 9, 0, 0, 0, moscow),
+		},
+		{
+			name:  "iana name with prefix",
+			tzid:  "/mozilla.org/20050126_1/Europe/Moscow",
+			value: "20260310T090000",
+			want:  time.Date(2026, 3, 10, 9, 0, 0, 0, moscow),
+		},
+		{
+			name:  "unknown timezone falls back to UTC",
+			tzid:  "Mars Standard Time",
+			value: "20260310T090000",
+			want:  time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prop := ical.NewProp(ical.PropDateTimeStart)
+			prop.Value = tt.value
+			prop.Params.Set(ical.ParamTimezoneID, tt.tzid)
+			got, err := resolver.dateTime(prop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("dateTime(%s:%s) = %v, want %v", tt.tzid, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		tzid string
+		want string
+	}{
+		{"iana name", "Europe/Berlin", "Europe/Berlin"},
+		{"windows name", "Russian Standard Time", "Europe/Moscow"},
+		{"iana name with prefix", "/mozilla.org/20050126_1/Europe/Moscow", "Europe/Moscow"},
+		{"unknown name", "Mars Standard Time", ""},
+		{"empty name", "", ""},
+		{"local", "Local", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := loadLocation(tt.tzid)
+			got := ""
+			if location != nil {
+				got = location.String()
+			}
+			if got != tt.want {
+				t.Errorf("loadLocation(%q) = %q, want %q", tt.tzid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandOccurrencesInCustomTimezone(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC)
+	events := convertTestCalendar(t, customTimezoneIcs, time.UTC, start, end)
+	// Local time 09:00 is kept after daylight saving time starts on 29.03
+	wantStarts := []time.Time{
+		time.Date(2026, 3, 19, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 26, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, 4, 2, 7, 0, 0, 0, time.UTC),
+	}
+	if len(events) != len(wantStarts) {
+		t.Fatalf("got %d occurrences, want %d", len(events), len(wantStarts))
+	}
+	for i, event := range events {
+		if !event.StartTime.Equal(wantStarts[i]) {
+			t.Errorf("occurrence %d starts at %v, want %v", i, event.StartTime, wantStarts[i])
+		}
+		if length := event.EndTime.Sub(event.StartTime); length != time.Hour {
+			t.Errorf("occurrence %d lasts %v, want 1h", i, length)
+		}
+	}
+}
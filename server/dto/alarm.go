@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// Alarm is VALARM trigger of event, it's either relative to start or end of event or absolute
+type Alarm struct {
+	Trigger    time.Duration
+	RelatedEnd bool
+	Time       *time.Time
+}
+
+// GetTime calculates moment of alarm for event occurrence
+func (a *Alarm) GetTime(event *Event) time.Time {
+	if a.Time != nil {
+		return *a.Time
+	}
+	if a.RelatedEnd {
+		return event.EndTime.Add(a.Trigger)
+	}
+	return event.StartTime.Add(a.Trigger)
+}
+
+// IsRelatedToEnd checks relative alarm fires before or after end of event, absolute alarms are related to start
+func (a *Alarm) IsRelatedToEnd() bool {
+	return a.Time == nil && a.RelatedEnd
+}
+
+// GetAnchor returns moment of event which alarm is related to
+func (a *Alarm) GetAnchor(event *Event) time.Time {
+	if a.IsRelatedToEnd() {
+		return event.EndTime
+	}
+	return event.StartTime
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "strings"
+
+const (
+	PartStatNeedsAction = "NEEDS-ACTION"
+	PartStatAccepted    = "ACCEPTED"
+	PartStatDeclined    = "DECLINED"
+	PartStatTentative   = "TENTATIVE"
+)
+
+type Attendee struct {
+	Email    string
+	Name     string
+	Role     string
+	PartStat string
+}
+
+func (a *Attendee) GetDisplayName() string {
+	if a.Name == "" {
+		return a.Email
+	}
+	return a.Name
+}
+
+// HasEmail compares emails case insensitive
+func (a *Attendee) HasEmail(emails []string) bool {
+	for _, email := range emails {
+		if strings.EqualFold(a.Email, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// BusyInterval is time when user is busy, Names are filled only if user shares event titles
+type BusyInterval struct {
+	StartTime time.Time
+	EndTime   time.Time
+	Names     []string
+}
@@ -0,0 +1,13 @@
+package dto
+
+type Calendar struct {
+	Path string
+	Name string
+}
+
+func (c *Calendar) GetDisplayName() string {
+	if c.Name == "" {
+		return c.Path
+	}
+	return c.Name
+}
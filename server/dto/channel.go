@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+)
+
+// ChannelSubscription links calendar of user to channel, events are loaded with credentials of that user
+type ChannelSubscription struct {
+	UserId   string
+	Calendar Calendar
+}
+
+// ChannelSettings keep calendars subscribed by channel, timezone, digest time and language are taken from the first subscriber
+type ChannelSettings struct {
+	Subscriptions   []ChannelSubscription
+	TimeZone        string
+	DailyNotifyTime *time.Time
+	Language        string
+}
+
+func (s *ChannelSettings) HasCalendar(path string) bool {
+	for _, subscription := range s.Subscriptions {
+		if subscription.Calendar.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSubscriptions drops subscriptions which match filter and returns whether any was dropped
+func (s *ChannelSettings) RemoveSubscriptions(filter func(subscription ChannelSubscription) bool) bool {
+	var subscriptions []ChannelSubscription
+	for _, subscription := range s.Subscriptions {
+		if !filter(subscription) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	removed := len(subscriptions) != len(s.Subscriptions)
+	s.Subscriptions = subscriptions
+	return removed
+}
+
+// GetLocation returns location of channel timezone, UTC is used if timezone is unknown
+func (s *ChannelSettings) GetLocation() *time.Location {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func (s *ChannelSettings) GetNow() time.Time {
+	return time.Now().In(s.GetLocation()).Truncate(time.Minute)
+}
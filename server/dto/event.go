@@ -6,45 +6,85 @@ import (
 	"time"
 )
 
-const (
-	timeFormat = "15:04"
-)
+const timeFormat = "15:04"
 
 type Event struct {
-	Id                  string
-	Name                string
-	Description         string
-	Url                 string
-	TimeZone            string
-	StartTime           time.Time
-	EndTime             time.Time
-	StartTimeHourMinute int
-	EndTimeHourMinute   int
-	LastModifiedTime    time.Time
+	Id               string
+	RecurrenceId     string
+	Name             string
+	Description      string
+	Location         string
+	Url              string
+	ConferenceUrl    string
+	TimeZone         string
+	CalendarPath     string
+	CalendarName     string
+	ObjectPath       string
+	StartTime        time.Time
+	EndTime          time.Time
+	AllDay           bool
+	Organizer        *Attendee
+	Attendees        []Attendee
+	UserPartStat     string
+	Alarms           []Alarm
+	LastModifiedTime time.Time
 }
 
 func NewEvent(
 	Id string,
+	RecurrenceId string,
 	Name string,
 	Description string,
 	Url string,
 	TimeZone string,
 	StartTime time.Time,
 	EndTime time.Time,
+	AllDay bool,
 	LastModifiedTime time.Time,
 ) *Event {
 	return &Event{
-		Id:                  Id,
-		Name:                Name,
-		Description:         Description,
-		Url:                 Url,
-		TimeZone:            TimeZone,
-		StartTime:           StartTime,
-		EndTime:             EndTime,
-		LastModifiedTime:    LastModifiedTime,
-		StartTimeHourMinute: util.HoursMinutes(StartTime),
-		EndTimeHourMinute:   util.HoursMinutes(EndTime),
+		Id:               Id,
+		RecurrenceId:     RecurrenceId,
+		Name:             Name,
+		Description:      Description,
+		Url:              Url,
+		TimeZone:         TimeZone,
+		StartTime:        StartTime,
+		EndTime:          EndTime,
+		AllDay:           AllDay,
+		LastModifiedTime: LastModifiedTime,
+	}
+}
+
+// NewOccurrence copies recurring event for concrete occurrence
+func (e *Event) NewOccurrence(RecurrenceId string, StartTime time.Time, EndTime time.Time) Event {
+	occurrence := *e
+	occurrence.RecurrenceId = RecurrenceId
+	occurrence.StartTime = StartTime
+	occurrence.EndTime = EndTime
+	return occurrence
+}
+
+// InLocation shows event in location of user
+func (e *Event) InLocation(location *time.Location) {
+	e.StartTime = e.StartTime.In(location)
+	e.EndTime = e.EndTime.In(location)
+}
+
+// GetOccurrenceId identifies concrete occurrence of recurring event
+func (e *Event) GetOccurrenceId() string {
+	if e.RecurrenceId == "" {
+		return e.Id
 	}
+	return e.Id + "_" + e.RecurrenceId
+}
+
+// Overlaps checks event intersects [start, end]
+func (e *Event) Overlaps(start time.Time, end time.Time) bool {
+	if e.StartTime.After(end) {
+		return false
+	}
+	return e.EndTime.After(start) || e.StartTime.Equal(start)
 }
 
 func (e *Event) GetStartTimeFormatted() string {
@@ -55,30 +95,103 @@ func (e *Event) GetEndTimeFormatted() string {
 	return e.EndTime.Format(timeFormat)
 }
 
-func (e *Event) GetDescriptionFormatted() string {
-	return strings.Replace(e.Description, "\\n", "\n", -1)
+// GetDatesFormatted shows dates of whole day event in language, end date is inclusive
+func (e *Event) GetDatesFormatted(language string) string {
+	if !e.IsMultiDay() {
+		return util.FormatShortDate(e.StartTime, language)
+	}
+	return util.FormatShortDate(e.StartTime, language) + " - " +
+		util.FormatShortDate(e.getLastInstant().In(e.StartTime.Location()), language)
 }
 
-func (e *Event) StartBefore(dt time.Time) bool {
-	return util.HoursMinutes(dt) > e.StartTimeHourMinute
+// IsMultiDay checks event lasts more than one calendar day
+func (e *Event) IsMultiDay() bool {
+	lastInstant := e.getLastInstant().In(e.StartTime.Location())
+	return lastInstant.Year() != e.StartTime.Year() || lastInstant.YearDay() != e.StartTime.YearDay()
 }
 
-func (e *Event) StartBeforeOrEquals(dt time.Time) bool {
-	return util.HoursMinutes(dt) >= e.StartTimeHourMinute
+// IsWholeDay checks event should be shown as all day and skipped in reminders and statuses
+func (e *Event) IsWholeDay() bool {
+	return e.AllDay || e.IsMultiDay()
 }
 
-func (e *Event) StartAfter(dt time.Time) bool {
-	return util.HoursMinutes(dt) < e.StartTimeHourMinute
+func (e *Event) getLastInstant() time.Time {
+	if !e.EndTime.After(e.StartTime) {
+		return e.StartTime
+	}
+	return e.EndTime.Add(-time.Nanosecond)
+}
+
+// ResolveUserPartStat finds participation status of user with one of emails.
+// Status is empty if user isn't invited to event
+func (e *Event) ResolveUserPartStat(emails []string) string {
+	if e.Organizer != nil && e.Organizer.HasEmail(emails) {
+		return PartStatAccepted
+	}
+	for _, attendee := range e.Attendees {
+		if attendee.HasEmail(emails) {
+			return attendee.PartStat
+		}
+	}
+	return ""
+}
+
+func (e *Event) IsDeclined() bool {
+	return e.UserPartStat == PartStatDeclined
+}
+
+func (e *Event) IsTentative() bool {
+	return e.UserPartStat == PartStatTentative
+}
+
+func (e *Event) IsNeedsAction() bool {
+	return e.UserPartStat == PartStatNeedsAction
+}
+
+// GetAlarmAt finds event alarm which fires at minute of dt, it's nil if there isn't such alarm.
+// Only cached events are checked, so alarms earlier than cache window before event aren't found
+func (e *Event) GetAlarmAt(dt time.Time) *Alarm {
+	for i := range e.Alarms {
+		if e.Alarms[i].GetTime(e).Truncate(time.Minute).Equal(dt.Truncate(time.Minute)) {
+			return &e.Alarms[i]
+		}
+	}
+	return nil
+}
+
+// Matches checks normalized query is contained in summary, description, location or attendees of event
+func (e *Event) Matches(query string) bool {
+	fields := []string{e.Name, e.GetDescriptionFormatted(), e.Location}
+	if e.Organizer != nil {
+		fields = append(fields, e.Organizer.Name, e.Organizer.Email)
+	}
+	for _, attendee := range e.Attendees {
+		fields = append(fields, attendee.Name, attendee.Email)
+	}
+	for _, field := range fields {
+		if strings.Contains(util.NormalizeSearchText(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Event) GetDescriptionFormatted() string {
+	return strings.Replace(e.Description, "\\n", "\n", -1)
 }
 
+// StartEquals checks event starts at minute of dt, events of other days don't match
 func (e *Event) StartEquals(dt time.Time) bool {
-	return dt.Hour() == e.StartTime.Hour() && dt.Minute() == e.StartTime.Minute()
+	return e.StartTime.Truncate(time.Minute).Equal(dt.Truncate(time.Minute))
 }
 
-func (e *Event) EndAfterOrEquals(dt time.Time) bool {
-	return util.HoursMinutes(dt) <= e.EndTimeHourMinute
+// IsActiveAt checks dt is in [start, end) of event, so event isn't active at minute of its end
+func (e *Event) IsActiveAt(dt time.Time) bool {
+	return !e.StartTime.After(dt) && e.EndTime.After(dt)
 }
 
-func (e *Event) EndBefore(dt time.Time) bool {
-	return util.HoursMinutes(dt) > e.EndTimeHourMinute
+// IsMeeting checks event has time, so it could be reminded and shown in status.
+// Event could cross midnight or last several days, status is kept until its end
+func (e *Event) IsMeeting() bool {
+	return !e.AllDay
 }
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventIsActiveAt(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lateEvent := Event{
+		StartTime: time.Date(2026, 3, 10, 23, 30, 0, 0, location),
+		EndTime:   time.Date(2026, 3, 11, 0, 30, 0, 0, location),
+	}
+	tests := []struct {
+		name  string
+		event Event
+		dt    time.Time
+		want  bool
+	}{
+		{"before start", lateEvent, time.Date(2026, 3, 10, 23, 29, 0, 0, location), false},
+		{"at start", lateEvent, time.Date(2026, 3, 10, 23, 30, 0, 0, location), true},
+		{"before midnight", lateEvent, time.Date(2026, 3, 10, 23, 59, 0, 0, location), true},
+		{"after midnight", lateEvent, time.Date(2026, 3, 11, 0, 10, 0, 0, location), true},
+		{"after midnight in UTC", lateEvent, time.Date(2026, 3, 10, 21, 10, 0, 0, time.UTC), true},
+		{"at end", lateEvent, time.Date(2026, 3, 11, 0, 30, 0, 0, location), false},
+		{"the next evening", lateEvent, time.Date(2026, 3, 11, 23, 40, 0, 0, location), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.IsActiveAt(tt.dt); got != tt.want {
+				t.Errorf("IsActiveAt(%v) = %v, want %v", tt.dt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventIsMeeting(t *testing.T) {
+	start := time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		event Event
+		want  bool
+	}{
+		{"hour", Event{StartTime: start, EndTime: start.Add(time.Hour)}, true},
+		{"crosses midnight", Event{StartTime: start.Add(13 * time.Hour), EndTime: start.Add(15 * time.Hour)}, true},
+		{"several days", Event{StartTime: start, EndTime: start.Add(32 * time.Hour)}, true},
+		{"all day", Event{StartTime: start, EndTime: start.AddDate(0, 0, 1), AllDay: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.event.IsMeeting(); got != tt.want {
+				t.Errorf("IsMeeting() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,33 +4,101 @@ import (
 	"time"
 )
 
+const (
+	EventAlarmsOff        = "off"
+	EventAlarmsInstead    = "instead"
+	EventAlarmsAdditional = "additional"
+)
+
 type Settings struct {
-	TenMinutesNotify   bool
-	OneMinutesNotify   bool
+	// ReminderOffsets are minutes before event start to send reminders, 0 means at start
+	ReminderOffsets    []int
 	ChangeStatusOnMeet bool
-	Calendar           string
-	TimeZone           string
-	DailyNotifyTime    *time.Time
+	// EventAlarms defines how VALARM reminders of events are used with reminder offsets
+	EventAlarms string
+	// ShareEventTitles allows colleagues to see titles of events in /calendar busy
+	ShareEventTitles bool
+	// ThreadedNotifications replies reminders and updates in thread of digest or the first notification of event
+	ThreadedNotifications bool
+	// WorkingHours limit time of meetings found by /calendar findtime, nil means default working hours
+	WorkingHours *WorkingHours `json:",omitempty"`
+	// Deprecated: TenMinutesNotify and OneMinutesNotify are kept only to migrate settings saved before ReminderOffsets
+	TenMinutesNotify bool `json:",omitempty"`
+	OneMinutesNotify bool `json:",omitempty"`
+	// Deprecated: Calendar is kept only to migrate settings saved before multiple calendars support
+	Calendar        string `json:",omitempty"`
+	Calendars       []Calendar
+	TimeZone        string
+	DailyNotifyTime *time.Time
 }
 
 func DefaultSettings() *Settings {
 	defaultDailyNotifyTime := time.Date(1, 1, 1, 7, 0, 0, 0, time.UTC)
 	return &Settings{
-		TenMinutesNotify:   true,
-		OneMinutesNotify:   true,
+		ReminderOffsets:    []int{10, 1},
 		ChangeStatusOnMeet: true,
-		Calendar:           "",
-		TimeZone:           "",
-		DailyNotifyTime:    &defaultDailyNotifyTime,
+		ShareEventTitles:   false,
+		// Settings saved before have it false, so they keep flat notifications
+		ThreadedNotifications: true,
+		EventAlarms:           EventAlarmsOff,
+		Calendars:             []Calendar{},
+		TimeZone:              "",
+		DailyNotifyTime:       &defaultDailyNotifyTime,
 	}
 }
 
+// Migrate moves values of deprecated fields to actual ones
+func (s *Settings) Migrate() {
+	if len(s.Calendars) == 0 && s.Calendar != "" {
+		s.Calendars = []Calendar{{Path: s.Calendar}}
+	}
+	s.Calendar = ""
+	if s.ReminderOffsets == nil {
+		s.ReminderOffsets = []int{}
+		if s.TenMinutesNotify {
+			s.ReminderOffsets = append(s.ReminderOffsets, 10)
+		}
+		if s.OneMinutesNotify {
+			s.ReminderOffsets = append(s.ReminderOffsets, 1)
+		}
+	}
+	s.TenMinutesNotify = false
+	s.OneMinutesNotify = false
+}
+
+func (s *Settings) UseReminderOffsets() bool {
+	return s.EventAlarms != EventAlarmsInstead
+}
+
+func (s *Settings) UseEventAlarms() bool {
+	return s.EventAlarms == EventAlarmsInstead || s.EventAlarms == EventAlarmsAdditional
+}
+
+func (s *Settings) HasCalendar(path string) bool {
+	for _, c := range s.Calendars {
+		if c.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Settings) GetWorkingHours() *WorkingHours {
+	if s.WorkingHours == nil {
+		return DefaultWorkingHours()
+	}
+	return s.WorkingHours
+}
+
+// GetUserLocation returns location of user timezone, UTC is used if timezone is unknown
 func (s *Settings) GetUserLocation() *time.Location {
-	location, _ := time.LoadLocation(s.TimeZone)
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
 	return location
 }
 
 func (s *Settings) GetUserNow() time.Time {
-	location, _ := time.LoadLocation(s.TimeZone)
-	return time.Now().In(location).Truncate(time.Minute)
+	return time.Now().In(s.GetUserLocation()).Truncate(time.Minute)
 }
@@ -0,0 +1,7 @@
+package dto
+
+// SyncState keeps markers of the last synchronization of calendar collection
+type SyncState struct {
+	SyncToken string
+	CTag      string
+}
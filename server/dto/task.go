@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"time"
+)
+
+const (
+	TaskStatusCompleted = "COMPLETED"
+	TaskStatusCancelled = "CANCELLED"
+)
+
+// Task is VTODO of calendar, only tasks with due date are loaded
+type Task struct {
+	Id               string
+	Name             string
+	Description      string
+	Url              string
+	CalendarPath     string
+	CalendarName     string
+	ObjectPath       string
+	DueTime          time.Time
+	AllDay           bool
+	Status           string
+	LastModifiedTime time.Time
+}
+
+// InLocation shows task in location of user
+func (t *Task) InLocation(location *time.Location) {
+	t.DueTime = t.DueTime.In(location)
+}
+
+func (t *Task) IsOpen() bool {
+	return t.Status != TaskStatusCompleted && t.Status != TaskStatusCancelled
+}
+
+// IsOverdue checks due time is passed, task with due date is overdue only on the next day
+func (t *Task) IsOverdue(now time.Time) bool {
+	if t.AllDay {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return t.DueTime.Before(today)
+	}
+	return t.DueTime.Before(now)
+}
+
+func (t *Task) IsDueOn(day time.Time) bool {
+	return util.IsSameDay(t.DueTime, day, day.Location())
+}
+
+// IsDueAt checks task with due time should be reminded at minute of dt
+func (t *Task) IsDueAt(dt time.Time) bool {
+	return !t.AllDay && t.DueTime.Truncate(time.Minute).Equal(dt.Truncate(time.Minute))
+}
+
+func (t *Task) GetDueFormatted(language string) string {
+	if t.AllDay {
+		return util.FormatShortDate(t.DueTime, language)
+	}
+	return util.FormatShortDate(t.DueTime, language) + " " + t.DueTime.Format(timeFormat)
+}
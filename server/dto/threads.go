@@ -0,0 +1,74 @@
+package dto
+
+import "time"
+
+const threadDayFormat = "2006-01-02"
+
+// Threads keeps root posts of notifications, so later notifications are replied in their threads,
+// and posts which show events, so they are edited when events change
+type Threads struct {
+	// DigestDay is date of digest in user location
+	DigestDay    string
+	DigestPostId string
+	// EventPostIds keeps the first notification post by occurrence id of event
+	EventPostIds map[string]string
+	// MentionPostIds keeps all posts which show event by occurrence id
+	MentionPostIds map[string][]string
+}
+
+func NewThreads() *Threads {
+	return &Threads{
+		EventPostIds:   make(map[string]string),
+		MentionPostIds: make(map[string][]string),
+	}
+}
+
+// AddMentions remembers post shows events
+func (t *Threads) AddMentions(postId string, events []Event) {
+	if postId == "" {
+		return
+	}
+	for _, event := range events {
+		occurrenceId := event.GetOccurrenceId()
+		t.MentionPostIds[occurrenceId] = append(t.MentionPostIds[occurrenceId], postId)
+	}
+}
+
+// SetDigest remembers digest of day, events of digest are replied in its thread
+func (t *Threads) SetDigest(day time.Time, postId string, events []Event) {
+	t.DigestDay = day.Format(threadDayFormat)
+	t.DigestPostId = postId
+	for _, event := range events {
+		t.EventPostIds[event.GetOccurrenceId()] = postId
+	}
+}
+
+// GetDigestPostId returns digest of day, it's empty if digest of day wasn't sent
+func (t *Threads) GetDigestPostId(day time.Time) string {
+	if t.DigestDay != day.Format(threadDayFormat) {
+		return ""
+	}
+	return t.DigestPostId
+}
+
+// Prune forgets threads of events which aren't cached anymore, true is returned if something was forgotten
+func (t *Threads) Prune(events []Event) bool {
+	occurrenceIds := make(map[string]bool, len(events))
+	for _, event := range events {
+		occurrenceIds[event.GetOccurrenceId()] = true
+	}
+	pruned := false
+	for occurrenceId := range t.EventPostIds {
+		if !occurrenceIds[occurrenceId] {
+			delete(t.EventPostIds, occurrenceId)
+			pruned = true
+		}
+	}
+	for occurrenceId := range t.MentionPostIds {
+		if !occurrenceIds[occurrenceId] {
+			delete(t.MentionPostIds, occurrenceId)
+			pruned = true
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+// TimeSlot is free time common for all participants of meeting
+type TimeSlot struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// WorkingHours are weekdays and minutes of day when user can be invited to meetings in his timezone
+type WorkingHours struct {
+	Weekdays    []time.Weekday
+	StartMinute int
+	EndMinute   int
+}
+
+func DefaultWorkingHours() *WorkingHours {
+	return &WorkingHours{
+		Weekdays:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		StartMinute: 9 * 60,
+		EndMinute:   18 * 60,
+	}
+}
+
+// Contains checks slot is inside one working day in location
+func (w *WorkingHours) Contains(slot TimeSlot, location *time.Location) bool {
+	localStart := slot.StartTime.In(location)
+	if !w.hasWeekday(localStart.Weekday()) {
+		return false
+	}
+	year, month, day := localStart.Date()
+	dayStart := time.Date(year, month, day, 0, w.StartMinute, 0, 0, location)
+	dayEnd := time.Date(year, month, day, 0, w.EndMinute, 0, 0, location)
+	return !localStart.Before(dayStart) && !slot.EndTime.After(dayEnd)
+}
+
+func (w *WorkingHours) hasWeekday(weekday time.Weekday) bool {
+	for _, workingDay := range w.Weekdays {
+		if workingDay == weekday {
+			return true
+		}
+	}
+	return false
+}
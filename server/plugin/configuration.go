@@ -3,6 +3,7 @@ package plugin
 import (
 	"reflect"
 
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/service"
 	"github.com/pkg/errors"
 )
 
@@ -18,7 +19,15 @@ import (
 // If you add non-reference types to your configuration struct, be sure to rewrite Clone as a deep
 // copy appropriate for your types.
 type configuration struct {
-	ServerUrl string `json:"ServerUrl"`
+	ServerUrl          string `json:"ServerUrl"`
+	DigestTemplate     string `json:"DigestTemplate"`
+	ReminderTemplate   string `json:"ReminderTemplate"`
+	UpdateTemplate     string `json:"UpdateTemplate"`
+	WelcomeTemplate    string `json:"WelcomeTemplate"`
+	FindTimeSlotsLimit int    `json:"FindTimeSlotsLimit"`
+
+	// templates are parsed from fields above, nil templates use built-in format
+	templates *service.MessageTemplates
 }
 
 // Clone shallow copies the configuration. Your implementation may require a deep copy if
@@ -83,6 +92,16 @@ func (p *Plugin) OnConfigurationChange() error {
 	if configuration.ServerUrl == "" {
 		return errors.New("CALDav ServerUrl is required")
 	}
+	templates, err := service.NewMessageTemplates(
+		configuration.DigestTemplate,
+		configuration.ReminderTemplate,
+		configuration.UpdateTemplate,
+		configuration.WelcomeTemplate,
+	)
+	if err != nil {
+		return errors.Wrap(err, "Invalid message template")
+	}
+	configuration.templates = templates
 	p.setConfiguration(configuration)
 
 	if p.service != nil {
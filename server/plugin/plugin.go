@@ -49,6 +49,7 @@ type Service struct {
 	sender    *service.Sender
 	workspace *service.Workspace
 	user      *service.User
+	channel   *service.Channel
 	scheduler *service.Scheduler
 }
 
@@ -122,11 +123,12 @@ func (p *Plugin) registerRepos() {
 
 func (p *Plugin) registerServices() {
 	p.service = &Service{}
-	p.service.calendar = service.NewCalendarService(p.logger, p.API, p.getConfiguration().ServerUrl, p.repo.credentials)
-	p.service.sender = service.NewSenderService(manifest.ID, p.botId, p.logger, p.API, p.supportedUserCustomStatus(), p.serverConfig)
+	p.service.calendar = service.NewCalendarService(p.logger, p.API, p.getConfiguration().ServerUrl, p.repo.credentials, p.getConfiguration().FindTimeSlotsLimit)
+	p.service.sender = service.NewSenderService(manifest.ID, p.botId, p.logger, p.API, p.supportedUserCustomStatus(), p.serverConfig, p.getConfiguration().templates)
 	p.service.workspace = service.NewWorkspaceService(p.repo.workspace)
 	p.service.user = service.NewUserService(p.logger, p.API, p.supportedUserCustomStatus(), p.repo.credentials, p.service.sender, p.service.calendar)
-	p.service.scheduler = service.NewSchedulerService(p.logger, p.API, p.service.workspace, p.service.user)
+	p.service.channel = service.NewChannelService(p.logger, p.API, p.service.sender, p.service.calendar, p.service.workspace)
+	p.service.scheduler = service.NewSchedulerService(p.logger, p.API, p.service.workspace, p.service.user, p.service.channel)
 
 	p.service.scheduler.InitCronJobs()
 }
@@ -135,7 +137,7 @@ func (p *Plugin) registerControllers() {
 	p.controller = &Controller{}
 	p.controller.http = controller.NewHttpController(p.API, manifest.Version,
 		p.service.calendar, p.service.user, p.service.sender, p.service.scheduler, p.service.workspace)
-	p.controller.hook = controller.NewHookController(p.API, p.botId, p.service.calendar, p.service.user, p.service.sender, p.service.scheduler, p.service.workspace)
+	p.controller.hook = controller.NewHookController(p.API, p.botId, p.service.calendar, p.service.user, p.service.channel, p.service.sender, p.service.scheduler, p.service.workspace)
 }
 
 func (p *Plugin) getServerVersion() *semver.Version {
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"encoding/json"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+	"strconv"
+	"time"
+)
+
+func SaveChannelSettings(pluginAPI plugin.API, channelId string, settings dto.ChannelSettings) {
+	jsonVal, marshalErr := json.Marshal(settings)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal settings for channel:"+channelId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(channelId+channelSettingsKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save settings to store for channel:"+channelId, mlog.Err(err))
+	}
+}
+
+// GetChannelSettings returns nil if channel has no subscriptions
+func GetChannelSettings(pluginAPI plugin.API, channelId string) *dto.ChannelSettings {
+	bytes, kvErr := pluginAPI.KVGet(channelId + channelSettingsKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting settings from store for channel:"+channelId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return nil
+	}
+	var settings *dto.ChannelSettings
+	err := json.Unmarshal(bytes, &settings)
+	if err != nil {
+		mlog.Warn("Error on parse settings from storage for channel:"+channelId, mlog.Err(err))
+		return nil
+	}
+	return settings
+}
+
+func SaveChannelEvents(pluginAPI plugin.API, channelId string, events []dto.Event) {
+	jsonVal, marshalErr := json.Marshal(events)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal events for channel:"+channelId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(channelId+channelEventsKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save events to store for channel:"+channelId, mlog.Err(err))
+	}
+}
+
+func GetChannelEvents(pluginAPI plugin.API, channelId string) []dto.Event {
+	bytes, kvErr := pluginAPI.KVGet(channelId + channelEventsKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting events from storage for channel:"+channelId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return nil
+	}
+	var events []dto.Event
+	err := json.Unmarshal(bytes, &events)
+	if err != nil {
+		mlog.Warn("Error on parse events from storage for channel:"+channelId, mlog.Err(err))
+		return nil
+	}
+	return events
+}
+
+// SaveChannelEventsWindow remembers range of cached channel events
+func SaveChannelEventsWindow(pluginAPI plugin.API, channelId string, window dto.TimeSlot) {
+	jsonVal, marshalErr := json.Marshal(window)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal events window for channel:"+channelId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(channelId+channelEventsWindowKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save events window to store for channel:"+channelId, mlog.Err(err))
+	}
+}
+
+func GetChannelEventsWindow(pluginAPI plugin.API, channelId string) *dto.TimeSlot {
+	bytes, kvErr := pluginAPI.KVGet(channelId + channelEventsWindowKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting events window from storage for channel:"+channelId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return nil
+	}
+	var window dto.TimeSlot
+	err := json.Unmarshal(bytes, &window)
+	if err != nil {
+		mlog.Warn("Error on parse events window from storage for channel:"+channelId, mlog.Err(err))
+		return nil
+	}
+	return &window
+}
+
+// SaveChannelSyncStates keeps sync states of subscribed calendars at the moment channel events were cached
+func SaveChannelSyncStates(pluginAPI plugin.API, channelId string, syncStates map[string]dto.SyncState) {
+	saveSyncStates(pluginAPI, channelId, channelSyncStatesKey, syncStates)
+}
+
+func GetChannelSyncStates(pluginAPI plugin.API, channelId string) map[string]dto.SyncState {
+	return getSyncStates(pluginAPI, channelId, channelSyncStatesKey)
+}
+
+func SaveChannelLastUpdate(pluginAPI plugin.API, channelId string, lastUpdate time.Time) {
+	err := pluginAPI.KVSet(channelId+channelLastUpdateKey, []byte(lastUpdate.Format(time.RFC3339)))
+	if err != nil {
+		mlog.Error("Error on save lastUpdated for channel:"+channelId, mlog.Err(err))
+	}
+}
+
+func GetChannelLastUpdate(pluginAPI plugin.API, channelId string) *time.Time {
+	lastUpdateBytes, _ := pluginAPI.KVGet(channelId + channelLastUpdateKey)
+	if lastUpdateBytes != nil {
+		lastUpdate, _ := time.Parse(time.RFC3339, string(lastUpdateBytes))
+		return &lastUpdate
+	}
+	return nil
+}
+
+func DeleteChannelCronJobIds(pluginAPI plugin.API, channelId string) {
+	eventErr := pluginAPI.KVDelete(channelId + channelEventCronIdKey)
+	if eventErr != nil {
+		pluginAPI.LogError("Error in delete "+channelEventCronIdKey, "channelId", channelId)
+	}
+	updateErr := pluginAPI.KVDelete(channelId + channelUpdateCronIdKey)
+	if updateErr != nil {
+		pluginAPI.LogError("Error in delete "+channelUpdateCronIdKey, "channelId", channelId)
+	}
+}
+
+func GetChannelCronJobIds(pluginAPI plugin.API, channelId string) (*int, *int) {
+	var eventCronId *int
+	var updateCronId *int
+	eventCronIdBytes, _ := pluginAPI.KVGet(channelId + channelEventCronIdKey)
+	if eventCronIdBytes != nil {
+		val, _ := strconv.Atoi(string(eventCronIdBytes))
+		eventCronId = &val
+	}
+	updateCronIdBytes, _ := pluginAPI.KVGet(channelId + channelUpdateCronIdKey)
+	if updateCronIdBytes != nil {
+		val, _ := strconv.Atoi(string(updateCronIdBytes))
+		updateCronId = &val
+	}
+	return eventCronId, updateCronId
+}
+
+func SaveChannelEventCronJob(pluginAPI plugin.API, channelId string, eventCronJobId int) {
+	err := pluginAPI.KVSet(channelId+channelEventCronIdKey, []byte(strconv.Itoa(eventCronJobId)))
+	if err != nil {
+		mlog.Error("Error on save EventCronId for channel:"+channelId, mlog.Err(err))
+	}
+}
+
+func SaveChannelUpdateCronJob(pluginAPI plugin.API, channelId string, updateCronJobId int) {
+	err := pluginAPI.KVSet(channelId+channelUpdateCronIdKey, []byte(strconv.Itoa(updateCronJobId)))
+	if err != nil {
+		mlog.Error("Error on save UpdateCronId for channel:"+channelId, mlog.Err(err))
+	}
+}
@@ -4,9 +4,25 @@ const (
 	credentialsKey     = ".credentials"
 	calendarHomeSetKey = ".calendarHomeSet"
 	eventsKey          = ".events"
+	tasksKey           = ".tasks"
+	eventsWindowKey    = ".eventsWindow"
 	lastUpdateKey      = ".lastUpdate"
+	syncStatesKey      = ".syncStates"
+	taskSyncStatesKey  = ".taskSyncStates"
 	settingsKey        = ".setting"
 	stateKey           = ".state"
+	threadsKey         = ".threads"
 	eventCronIdKey     = ".eventCronId"
 	updateCronIdKey    = ".updateCronId"
 )
+
+// Keys of channels are prefixed by channel id
+const (
+	channelSettingsKey     = ".channelSettings"
+	channelEventsKey       = ".channelEvents"
+	channelLastUpdateKey   = ".channelLastUpdate"
+	channelEventsWindowKey = ".channelEventsWindow"
+	channelSyncStatesKey   = ".channelSyncStates"
+	channelEventCronIdKey  = ".channelEventCronId"
+	channelUpdateCronIdKey = ".channelUpdateCronId"
+)
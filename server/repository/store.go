@@ -40,6 +40,81 @@ func GetUserCalendarLastUpdate(pluginAPI plugin.API, userId string) *time.Time {
 	return nil
 }
 
+// SaveEventsWindow remembers range of cached events
+func SaveEventsWindow(pluginAPI plugin.API, userId string, window dto.TimeSlot) {
+	jsonVal, marshalErr := json.Marshal(window)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal events window for user:"+userId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(userId+eventsWindowKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save events window to store for user:"+userId, mlog.Err(err))
+	}
+}
+
+func GetEventsWindow(pluginAPI plugin.API, userId string) *dto.TimeSlot {
+	bytes, kvErr := pluginAPI.KVGet(userId + eventsWindowKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting events window from storage for user:"+userId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return nil
+	}
+	var window dto.TimeSlot
+	err := json.Unmarshal(bytes, &window)
+	if err != nil {
+		mlog.Warn("Error on parse events window from storage for user:"+userId, mlog.Err(err))
+		return nil
+	}
+	return &window
+}
+
+func SaveSyncStates(pluginAPI plugin.API, userId string, syncStates map[string]dto.SyncState) {
+	saveSyncStates(pluginAPI, userId, syncStatesKey, syncStates)
+}
+
+// GetSyncStates returns sync states of user calendars by calendar path
+func GetSyncStates(pluginAPI plugin.API, userId string) map[string]dto.SyncState {
+	return getSyncStates(pluginAPI, userId, syncStatesKey)
+}
+
+// SaveTaskSyncStates keeps sync states of calendars at the moment their tasks were cached
+func SaveTaskSyncStates(pluginAPI plugin.API, userId string, syncStates map[string]dto.SyncState) {
+	saveSyncStates(pluginAPI, userId, taskSyncStatesKey, syncStates)
+}
+
+func GetTaskSyncStates(pluginAPI plugin.API, userId string) map[string]dto.SyncState {
+	return getSyncStates(pluginAPI, userId, taskSyncStatesKey)
+}
+
+func saveSyncStates(pluginAPI plugin.API, userId string, key string, syncStates map[string]dto.SyncState) {
+	jsonVal, marshalErr := json.Marshal(syncStates)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal sync states for user:"+userId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(userId+key, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save sync states to store for user:"+userId, mlog.Err(err))
+	}
+}
+
+func getSyncStates(pluginAPI plugin.API, userId string, key string) map[string]dto.SyncState {
+	syncStates := make(map[string]dto.SyncState)
+	bytes, kvErr := pluginAPI.KVGet(userId + key)
+	if kvErr != nil {
+		mlog.Error("Error on getting sync states from storage for user:"+userId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return syncStates
+	}
+	err := json.Unmarshal(bytes, &syncStates)
+	if err != nil {
+		mlog.Warn("Error on parse sync states from storage for user:"+userId, mlog.Err(err))
+		return make(map[string]dto.SyncState)
+	}
+	return syncStates
+}
+
 func DeleteUserCronJobIds(pluginAPI plugin.API, userId string) {
 	eventErr := pluginAPI.KVDelete(userId + eventCronIdKey)
 	if eventErr != nil {
@@ -110,6 +185,34 @@ func GetEvents(pluginAPI plugin.API, userId string) []dto.Event {
 	return events
 }
 
+func SaveTasks(pluginAPI plugin.API, userId string, tasks []dto.Task) {
+	jsonVal, marshalErr := json.Marshal(tasks)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal tasks for user:"+userId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(userId+tasksKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save tasks to store for user:"+userId, mlog.Err(err))
+	}
+}
+
+func GetTasks(pluginAPI plugin.API, userId string) []dto.Task {
+	bytes, kvErr := pluginAPI.KVGet(userId + tasksKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting tasks from storage for user:"+userId, mlog.Err(kvErr))
+	}
+	if bytes == nil {
+		return nil
+	}
+	var tasks []dto.Task
+	err := json.Unmarshal(bytes, &tasks)
+	if err != nil {
+		mlog.Warn("Error on parse tasks from storage for user:"+userId, mlog.Err(err))
+		return nil
+	}
+	return tasks
+}
+
 func SaveSettings(pluginAPI plugin.API, userId string, settings dto.Settings) {
 	settingsJson, marshalErr := json.Marshal(settings)
 	if marshalErr != nil {
@@ -136,6 +239,7 @@ func GetSettings(pluginAPI plugin.API, userId string) *dto.Settings {
 		mlog.Warn("Error on parse settings from storage for user:"+userId, mlog.Err(err))
 		return nil
 	}
+	settings.Migrate()
 	return settings
 }
 
@@ -167,3 +271,38 @@ func GetState(pluginAPI plugin.API, userId string) *dto.State {
 	}
 	return state
 }
+
+func SaveThreads(pluginAPI plugin.API, userId string, threads dto.Threads) {
+	jsonVal, marshalErr := json.Marshal(threads)
+	if marshalErr != nil {
+		mlog.Error("Error on marshal threads for user:"+userId, mlog.Err(marshalErr))
+	}
+	err := pluginAPI.KVSet(userId+threadsKey, jsonVal)
+	if err != nil {
+		mlog.Error("Error on save threads to store for user:"+userId, mlog.Err(err))
+	}
+}
+
+// GetThreads returns root posts of notifications, empty threads are returned if nothing was posted yet
+func GetThreads(pluginAPI plugin.API, userId string) *dto.Threads {
+	bytes, kvErr := pluginAPI.KVGet(userId + threadsKey)
+	if kvErr != nil {
+		mlog.Error("Error on getting threads from storage for user:"+userId, mlog.Err(kvErr))
+	}
+	threads := dto.NewThreads()
+	if bytes == nil {
+		return threads
+	}
+	err := json.Unmarshal(bytes, threads)
+	if err != nil {
+		mlog.Warn("Error on parse threads from storage for user:"+userId, mlog.Err(err))
+		return dto.NewThreads()
+	}
+	if threads.EventPostIds == nil {
+		threads.EventPostIds = make(map[string]string)
+	}
+	if threads.MentionPostIds == nil {
+		threads.MentionPostIds = make(map[string][]string)
+	}
+	return threads
+}
@@ -7,16 +7,18 @@ import (
 )
 
 type WorkspaceRepo struct {
-	logger    *util.Logger
-	pluginAPI plugin.API
-	usersKey  string
+	logger      *util.Logger
+	pluginAPI   plugin.API
+	usersKey    string
+	channelsKey string
 }
 
 func NewWorkspaceRepo(logger *util.Logger, plugin plugin.API) *WorkspaceRepo {
 	return &WorkspaceRepo{
-		logger:    logger,
-		pluginAPI: plugin,
-		usersKey:  "users",
+		logger:      logger,
+		pluginAPI:   plugin,
+		usersKey:    "users",
+		channelsKey: "channels",
 	}
 }
 
@@ -49,17 +51,66 @@ func (wr *WorkspaceRepo) GetUserIds() *map[string]bool {
 	return userIds
 }
 
+func (wr *WorkspaceRepo) SaveChannelIds(channelIds map[string]bool) {
+	channelIdsJson, marshalErr := json.Marshal(channelIds)
+	if marshalErr != nil {
+		wr.logger.LogError("Error on marshal channel ids", nil, marshalErr)
+	}
+	err := wr.pluginAPI.KVSet(wr.channelsKey, channelIdsJson)
+	if err != nil {
+		wr.logger.LogError("Error on save channel ids to storage", nil, err)
+	}
+}
+
+func (wr *WorkspaceRepo) GetChannelIds() *map[string]bool {
+	channelIdBytes, kvErr := wr.pluginAPI.KVGet(wr.channelsKey)
+	if kvErr != nil {
+		wr.logger.LogWarn("Couldn't find channel ids", nil, kvErr)
+	}
+	if channelIdBytes == nil {
+		return nil
+	}
+	var channelIds *map[string]bool
+	err := json.Unmarshal(channelIdBytes, &channelIds)
+	if err != nil {
+		wr.logger.LogWarn("Couldn't find channel ids", nil, err)
+		return nil
+	}
+	return channelIds
+}
+
 func (wr *WorkspaceRepo) DeleteUser(userId string) {
 	wr.deleteKeyForUser(userId, credentialsKey)
 	wr.deleteKeyForUser(userId, calendarHomeSetKey)
 	wr.deleteKeyForUser(userId, eventsKey)
+	wr.deleteKeyForUser(userId, tasksKey)
+	wr.deleteKeyForUser(userId, eventsWindowKey)
 	wr.deleteKeyForUser(userId, lastUpdateKey)
+	wr.deleteKeyForUser(userId, syncStatesKey)
+	wr.deleteKeyForUser(userId, taskSyncStatesKey)
 	wr.deleteKeyForUser(userId, settingsKey)
 	wr.deleteKeyForUser(userId, stateKey)
+	wr.deleteKeyForUser(userId, threadsKey)
 	wr.deleteKeyForUser(userId, eventCronIdKey)
 	wr.deleteKeyForUser(userId, updateCronIdKey)
 }
 
+// DeleteChannel keeps cron job ids, they are removed by scheduler together with jobs of channel
+func (wr *WorkspaceRepo) DeleteChannel(channelId string) {
+	wr.deleteKeyForChannel(channelId, channelSettingsKey)
+	wr.deleteKeyForChannel(channelId, channelEventsKey)
+	wr.deleteKeyForChannel(channelId, channelLastUpdateKey)
+	wr.deleteKeyForChannel(channelId, channelEventsWindowKey)
+	wr.deleteKeyForChannel(channelId, channelSyncStatesKey)
+}
+
+func (wr *WorkspaceRepo) deleteKeyForChannel(channelId string, key string) {
+	err := wr.pluginAPI.KVDelete(channelId + key)
+	if err != nil {
+		wr.logger.LogError("Error on delete "+key+" of channel "+channelId, nil, err)
+	}
+}
+
 func (wr *WorkspaceRepo) deleteKeyForUser(userId string, key string) {
 	err := wr.pluginAPI.KVDelete(userId + key)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/lugamuga/go-webdav"
 	"github.com/lugamuga/go-webdav/caldav"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
@@ -12,39 +13,61 @@ import (
 	"github.com/pkg/errors"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 )
 
+const yandexMailDomain = "yandex.ru"
+
 type Calendar struct {
 	logger          *util.Logger
 	pluginAPI       plugin.API
 	serverUrl       string
 	credentialsRepo *repository.CredentialsRepo
+	// findTimeSlotsLimit is the number of slots offered by /calendar findtime, default one is used if it's not positive
+	findTimeSlotsLimit int
 }
 
 func NewCalendarService(
 	logger *util.Logger,
 	plugin plugin.API,
 	serverUrl string,
-	credentialsRepo *repository.CredentialsRepo) *Calendar {
+	credentialsRepo *repository.CredentialsRepo,
+	findTimeSlotsLimit int) *Calendar {
 	return &Calendar{
-		logger:          logger,
-		pluginAPI:       plugin,
-		serverUrl:       serverUrl,
-		credentialsRepo: credentialsRepo,
+		logger:             logger,
+		pluginAPI:          plugin,
+		serverUrl:          serverUrl,
+		credentialsRepo:    credentialsRepo,
+		findTimeSlotsLimit: findTimeSlotsLimit,
 	}
 }
 
-func (c *Calendar) getClient(userId string) (*caldav.Client, error) {
+func (c *Calendar) getHttpClient(userId string) (webdav.HTTPClient, error) {
 	credentials := c.credentialsRepo.GetCredentials(userId)
 	if credentials == nil {
 		return nil, errors.New("Could not found credentials")
 	}
-	httpClient := webdav.HTTPClientWithBasicAuth(&http.Client{}, credentials.Login, credentials.Token)
+	return webdav.HTTPClientWithBasicAuth(&http.Client{}, credentials.Login, credentials.Token), nil
+}
+
+func (c *Calendar) getClient(userId string) (*caldav.Client, error) {
+	httpClient, err := c.getHttpClient(userId)
+	if err != nil {
+		return nil, err
+	}
 	client, err := caldav.NewClient(httpClient, c.serverUrl)
 	return client, err
 }
 
+func (c *Calendar) getDavClient(userId string) (*davClient, error) {
+	httpClient, err := c.getHttpClient(userId)
+	if err != nil {
+		return nil, err
+	}
+	return newDavClient(httpClient, c.serverUrl)
+}
+
 func (c *Calendar) GetCalendarHomeSet(userId string) (string, error) {
 	client, err := c.getClient(userId)
 	if err != nil {
@@ -69,45 +92,101 @@ func (c *Calendar) FindCalendars(userId string) ([]caldav.Calendar, error) {
 	return client.FindCalendars(calendarHomeSet)
 }
 
+// LoadCalendar reloads cached events of all days in cache window and returns events of today.
+// Cache is kept as is if calendars can't be loaded, so it isn't replaced by empty one
 func (c *Calendar) LoadCalendar(userId string) ([]dto.Event, error) {
-	events, _ := c.loadTodayEvents(userId)
+	now := getNowForLastUpdated()
+	// Sync states are taken before loading events, so changes made in between will be loaded next time
+	syncStates := c.loadSyncStates(userId)
+	start, end := c.GetCacheDateTimes(userId)
+	events, err := c.LoadEvents(userId, start, end)
+	if err != nil {
+		return nil, err
+	}
 	c.SortEvents(events)
 	repository.SaveEvents(c.pluginAPI, userId, events)
-	repository.SaveLastUpdate(c.pluginAPI, userId, getNowForLastUpdated())
-	return events, nil
+	repository.SaveEventsWindow(c.pluginAPI, userId, dto.TimeSlot{StartTime: start, EndTime: end})
+	repository.SaveSyncStates(c.pluginAPI, userId, syncStates)
+	repository.SaveLastUpdate(c.pluginAPI, userId, now)
+	c.refreshTasks(userId)
+	return getDayEvents(events, start.AddDate(0, 0, conf.EventCachePastDays)), nil
 }
 
-func (c *Calendar) LoadCalendarUpdates(userId string) ([]dto.Event, []dto.Event) {
+// LoadCalendarUpdates patches cached events by objects changed since the last synchronization
+// and returns added, updated and removed or cancelled events
+func (c *Calendar) LoadCalendarUpdates(userId string) ([]dto.Event, []dto.Event, []dto.Event) {
 	now := getNowForLastUpdated()
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
 	var lastUpdate = repository.GetUserCalendarLastUpdate(c.pluginAPI, userId)
+	start, end := c.GetCacheDateTimes(userId)
+	cachedWindow := repository.GetEventsWindow(c.pluginAPI, userId)
+	// Cache which can't be shifted to the current window is reloaded for all calendars
+	cacheOutdated := lastUpdate == nil || cachedWindow == nil || !isWindowShift(*cachedWindow, start)
 	if lastUpdate == nil {
 		lastUpdate = &now
 	}
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendars", &userId, err)
+		return nil, nil, nil
+	}
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get WebDAV client for calendars", &userId, err)
+		return nil, nil, nil
+	}
+	syncStates := repository.GetSyncStates(c.pluginAPI, userId)
+	cache := newEventsCache(repository.GetEvents(c.pluginAPI, userId), cachedWindow, cacheOutdated, *lastUpdate)
+
 	var events []dto.Event
 	var updatedEvents []dto.Event
 	var addedEvents []dto.Event
-	loadedEvents, _ := c.loadTodayEvents(userId)
-	existingEvents := repository.GetEvents(c.pluginAPI, userId)
-	existingEventById := convertor.SliceEventToMapById(existingEvents)
-	for _, event := range loadedEvents {
-		events = append(events, event)
-		if event.LastModifiedTime.After(*lastUpdate) && event.StartAfter(now) {
-			if _, ok := existingEventById[event.Id]; ok {
-				updatedEvents = append(updatedEvents, event)
-			} else {
-				addedEvents = append(addedEvents, event)
-			}
+	var removedEvents []dto.Event
+	userEmails := c.getUserEmails(userId)
+	for _, calendar := range userSettings.Calendars {
+		source := calendarSource{
+			userId:     userId,
+			client:     client,
+			dc:         dc,
+			calendar:   calendar,
+			syncState:  syncStates[calendar.Path],
+			userEmails: userEmails,
+		}
+		update, err := c.updateCalendar(source, cache, now, start, end)
+		events = append(events, update.events...)
+		if err != nil {
+			c.logger.LogWarn("Can't load updates for calendar "+calendar.Path, &userId, err)
+			continue
 		}
+		syncStates[calendar.Path] = update.syncState
+		addedEvents = append(addedEvents, update.added...)
+		updatedEvents = append(updatedEvents, update.updated...)
+		removedEvents = append(removedEvents, update.removed...)
 	}
+	events = distinctEvents(events)
 	c.SortEvents(events)
 	repository.SaveEvents(c.pluginAPI, userId, events)
+	repository.SaveEventsWindow(c.pluginAPI, userId, dto.TimeSlot{StartTime: start, EndTime: end})
+	repository.SaveSyncStates(c.pluginAPI, userId, syncStates)
 	repository.SaveLastUpdate(c.pluginAPI, userId, now)
-	return addedEvents, updatedEvents
+	c.refreshTasks(userId)
+	return addedEvents, updatedEvents, removedEvents
 }
 
-func (c *Calendar) loadTodayEvents(userId string) ([]dto.Event, error) {
-	start, end := c.GetTodayDateTimes(userId)
-	return c.LoadEvents(userId, start, end)
+// GetEvents returns events of [start, end] from cache if it covers range and was updated recently,
+// otherwise events are loaded from server
+func (c *Calendar) GetEvents(userId string, start time.Time, end time.Time) ([]dto.Event, error) {
+	window := repository.GetEventsWindow(c.pluginAPI, userId)
+	lastUpdate := repository.GetUserCalendarLastUpdate(c.pluginAPI, userId)
+	if window == nil || lastUpdate == nil || start.Before(window.StartTime) || end.After(window.EndTime) ||
+		time.Since(*lastUpdate) > conf.EventCacheMaxAgeMinutes*time.Minute {
+		return c.LoadEvents(userId, start, end)
+	}
+	events := filterEventsByRange(repository.GetEvents(c.pluginAPI, userId), start, end)
+	for i := range events {
+		events[i].InLocation(start.Location())
+	}
+	return events, nil
 }
 
 func (c *Calendar) LoadEvents(userId string, start time.Time, end time.Time) ([]dto.Event, error) {
@@ -115,27 +194,109 @@ func (c *Calendar) LoadEvents(userId string, start time.Time, end time.Time) ([]
 	userSettings := repository.GetSettings(c.pluginAPI, userId)
 	client, err := c.getClient(userId)
 	if err != nil {
-		c.logger.LogError("Can't get client for calendar "+userSettings.Calendar, &userId, err)
-		return events, errors.New("Can't get client for calendar")
+		c.logger.LogError("Can't get client for calendars", &userId, err)
+		return events, conf.NewError(conf.MsgCalendarClientError)
+	}
+	failedCalendars := 0
+	for _, calendar := range userSettings.Calendars {
+		eventDtos, err := c.loadCalendarEvents(client, calendar, start, end)
+		if err != nil {
+			c.logger.LogWarn("Can't load events for calendar "+calendar.Path, &userId, err)
+			failedCalendars++
+			continue
+		}
+		events = append(events, eventDtos...)
 	}
-	calendarObjects, err := c.queryCalendarEventsByTimeRange(client, userSettings.Calendar, start, end)
-	if calendarObjects == nil {
-		c.logger.LogError("Can't get events for calendar "+userSettings.Calendar, &userId, err)
-		return events, errors.New("Can't get events from calendar")
+	events = distinctEvents(events)
+	resolveUserPartStat(events, c.getUserEmails(userId))
+	if failedCalendars > 0 && failedCalendars == len(userSettings.Calendars) {
+		return events, conf.NewError(conf.MsgGetEventsError)
 	}
-	timezone, err := convertor.GetTimezone(calendarObjects)
+	return events, nil
+}
+
+func (c *Calendar) loadCalendarEvents(
+	client *caldav.Client,
+	calendar dto.Calendar,
+	start time.Time,
+	end time.Time) ([]dto.Event, error) {
+
+	calendarObjects, err := c.queryCalendarEventsByTimeRange(client, calendar.Path, start, end)
 	if err != nil {
-		c.logger.LogWarn("Can't get timezone for calendar "+userSettings.Calendar, &userId, err)
+		return nil, errors.Wrap(err, "Can't get events from calendar")
 	}
-	eventDtos, err := convertor.CalendarObjectToEventArray(calendarObjects, timezone)
+	return c.toCalendarEvents(calendarObjects, calendar, start, end)
+}
+
+func (c *Calendar) toCalendarEvents(
+	calendarObjects []caldav.CalendarObject,
+	calendar dto.Calendar,
+	start time.Time,
+	end time.Time) ([]dto.Event, error) {
+
+	// start is always in user location, events are shown in it
+	events, err := convertor.CalendarObjectToEventArray(calendarObjects, start.Location(), start, end)
 	if err != nil {
-		c.logger.LogWarn("Can't parse events for calendar "+userSettings.Calendar, &userId, err)
-		return events, errors.New("Can't parse events from calendar")
+		return nil, errors.Wrap(err, "Can't parse events from calendar")
+	}
+	for i := range events {
+		events[i].CalendarPath = calendar.Path
+		events[i].CalendarName = calendar.GetDisplayName()
 	}
-	events = append(events, eventDtos...)
 	return events, nil
 }
 
+// CreateEvent puts new event to calendar, user becomes organizer if event has attendees
+func (c *Calendar) CreateEvent(userId string, event dto.Event) error {
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendar "+event.CalendarPath, &userId, err)
+		return conf.NewError(conf.MsgCalendarClientError)
+	}
+	if len(event.Attendees) > 0 {
+		event.Organizer = &dto.Attendee{
+			Email: c.getAccountEmail(userId),
+		}
+	}
+	objectPath := strings.TrimSuffix(event.CalendarPath, "/") + "/" + event.Id + ".ics"
+	_, err = client.PutCalendarObject(objectPath, convertor.EventToCalendar(event))
+	if err != nil {
+		c.logger.LogError("Can't create event in calendar "+event.CalendarPath, &userId, err)
+		return conf.NewError(conf.MsgCreateEventError)
+	}
+	c.cacheCreatedEvent(userId, client, event, objectPath)
+	return nil
+}
+
+// cacheCreatedEvent puts created event into cache as server keeps it, so the next update doesn't report it as added
+func (c *Calendar) cacheCreatedEvent(userId string, client *caldav.Client, event dto.Event, objectPath string) {
+	start, end := c.GetCacheDateTimes(userId)
+	calendar := dto.Calendar{Path: event.CalendarPath}
+	for _, userCalendar := range repository.GetSettings(c.pluginAPI, userId).Calendars {
+		if userCalendar.Path == event.CalendarPath {
+			calendar = userCalendar
+		}
+	}
+	var created []dto.Event
+	object, err := client.GetCalendarObject(objectPath)
+	if err == nil {
+		created, err = c.toCalendarEvents([]caldav.CalendarObject{*object}, calendar, start, end)
+	}
+	if err != nil {
+		c.logger.LogWarn("Can't get created event "+objectPath, &userId, err)
+		event.ObjectPath = objectPath
+		event.CalendarName = calendar.GetDisplayName()
+		created = filterEventsByRange([]dto.Event{event}, start, end)
+	}
+	if len(created) == 0 {
+		return
+	}
+	resolveUserPartStat(created, c.getUserEmails(userId))
+	events := distinctEvents(append(repository.GetEvents(c.pluginAPI, userId), created...))
+	c.SortEvents(events)
+	repository.SaveEvents(c.pluginAPI, userId, events)
+}
+
 func (c *Calendar) queryCalendarEventsByTimeRange(
 	client *caldav.Client,
 	calendarPath string,
@@ -163,18 +324,104 @@ func (c *Calendar) GetTodayDateTimes(userId string) (time.Time, time.Time) {
 	return start, end
 }
 
+// GetCacheDateTimes returns window of cached events from the start of yesterday to the end of the next week
+func (c *Calendar) GetCacheDateTimes(userId string) (time.Time, time.Time) {
+	start, end := c.GetTodayDateTimes(userId)
+	return start.AddDate(0, 0, -conf.EventCachePastDays), end.AddDate(0, 0, conf.EventCacheFutureDays)
+}
+
+// SortEvents sorts events by start, events of several days are kept in order of days
 func (c *Calendar) SortEvents(events []dto.Event) {
 	sort.SliceStable(events, func(i, j int) bool {
-		if events[i].StartTime.Hour() != events[j].StartTime.Hour() {
-			return events[i].StartTime.Hour() < events[j].StartTime.Hour()
-		}
-		if events[i].StartTime.Minute() != events[j].StartTime.Minute() {
-			return events[i].StartTime.Minute() < events[j].StartTime.Minute()
+		if !events[i].StartTime.Equal(events[j].StartTime) {
+			return events[i].StartTime.Before(events[j].StartTime)
 		}
 		return events[i].Name < events[j].Name
 	})
 }
 
+// getUserEmails returns emails which could identify user in attendees of event
+func (c *Calendar) getUserEmails(userId string) []string {
+	var emails []string
+	if accountEmail := c.getAccountEmail(userId); accountEmail != "" {
+		emails = append(emails, accountEmail)
+	}
+	user, err := c.pluginAPI.GetUser(userId)
+	if err != nil {
+		c.logger.LogWarn("Can't get user for attendee matching", &userId, err)
+	} else if user.Email != "" {
+		emails = append(emails, user.Email)
+	}
+	return emails
+}
+
+// getAccountEmail returns email of calendar account, Yandex login could be used without domain
+func (c *Calendar) getAccountEmail(userId string) string {
+	credentials := c.credentialsRepo.GetCredentials(userId)
+	if credentials == nil {
+		return ""
+	}
+	if strings.Contains(credentials.Login, "@") {
+		return credentials.Login
+	}
+	return credentials.Login + "@" + yandexMailDomain
+}
+
+func resolveUserPartStat(events []dto.Event, userEmails []string) {
+	for i := range events {
+		events[i].UserPartStat = events[i].ResolveUserPartStat(userEmails)
+	}
+}
+
+// distinctEvents removes occurrences duplicated in several calendars
+func distinctEvents(events []dto.Event) []dto.Event {
+	var distinct []dto.Event
+	occurrenceIds := make(map[string]bool)
+	for _, event := range events {
+		if occurrenceIds[event.GetOccurrenceId()] {
+			continue
+		}
+		occurrenceIds[event.GetOccurrenceId()] = true
+		distinct = append(distinct, event)
+	}
+	return distinct
+}
+
+func filterEventsByCalendar(events []dto.Event, calendarPath string) []dto.Event {
+	var filtered []dto.Event
+	for _, event := range events {
+		if event.CalendarPath == calendarPath {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func filterEventsByRange(events []dto.Event, start time.Time, end time.Time) []dto.Event {
+	var filtered []dto.Event
+	for _, event := range events {
+		if event.Overlaps(start, end) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// getDayEvents returns events of day of dt in its location
+func getDayEvents(events []dto.Event, dt time.Time) []dto.Event {
+	start := time.Date(dt.Year(), dt.Month(), dt.Day(), 0, 0, 0, 0, dt.Location())
+	end := time.Date(dt.Year(), dt.Month(), dt.Day(), 23, 59, 59, 0, dt.Location())
+	return filterEventsByRange(events, start, end)
+}
+
+// isWindowShift checks cached window starts at midnight of user day not later than the current one
+// and still intersects it, otherwise timezone was changed or cache is too old to be patched
+func isWindowShift(cachedWindow dto.TimeSlot, start time.Time) bool {
+	cachedStart := cachedWindow.StartTime.In(start.Location())
+	return cachedStart.Hour() == 0 && cachedStart.Minute() == 0 &&
+		!cachedStart.After(start) && !cachedWindow.EndTime.Before(start)
+}
+
 func getNowForLastUpdated() time.Time {
 	return time.Now().UTC()
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"time"
+)
+
+// LoadChannelCalendarUpdates patches cached events of channel by changes of subscribed calendars since the last update,
+// calendars are read with credentials of their subscribers. Calendar which can't be loaded keeps its cached events,
+// so its events aren't reported as removed. Reload drops cache and loads all calendars entirely.
+// Participation status of subscriber isn't resolved, as events are shown to all members of channel
+func (c *Calendar) LoadChannelCalendarUpdates(
+	channelId string,
+	settings *dto.ChannelSettings,
+	reload bool) ([]dto.Event, []dto.Event, []dto.Event) {
+
+	now := getNowForLastUpdated()
+	lastUpdate := repository.GetChannelLastUpdate(c.pluginAPI, channelId)
+	start, end := GetChannelCacheDateTimes(settings)
+	cachedWindow := repository.GetChannelEventsWindow(c.pluginAPI, channelId)
+	cacheOutdated := reload || lastUpdate == nil || cachedWindow == nil || !isWindowShift(*cachedWindow, start)
+	if lastUpdate == nil {
+		lastUpdate = &now
+	}
+	syncStates := repository.GetChannelSyncStates(c.pluginAPI, channelId)
+	cache := newEventsCache(repository.GetChannelEvents(c.pluginAPI, channelId), cachedWindow, cacheOutdated, *lastUpdate)
+
+	var events []dto.Event
+	var addedEvents []dto.Event
+	var updatedEvents []dto.Event
+	var removedEvents []dto.Event
+	for _, subscription := range settings.Subscriptions {
+		userId := subscription.UserId
+		calendarPath := subscription.Calendar.Path
+		client, err := c.getClient(userId)
+		var dc *davClient
+		if err == nil {
+			dc, err = c.getDavClient(userId)
+		}
+		if err != nil {
+			c.logger.LogWarn("Can't get client for channel calendar "+calendarPath, &userId, err)
+			if !cacheOutdated {
+				events = append(events, filterEventsByRange(filterEventsByCalendar(cache.events, calendarPath), start, end)...)
+			}
+			continue
+		}
+		source := calendarSource{
+			userId:    userId,
+			client:    client,
+			dc:        dc,
+			calendar:  subscription.Calendar,
+			syncState: syncStates[calendarPath],
+		}
+		update, err := c.updateCalendar(source, cache, now, start, end)
+		events = append(events, update.events...)
+		if err != nil {
+			c.logger.LogWarn("Can't load updates for channel calendar "+calendarPath, &userId, err)
+			continue
+		}
+		syncStates[calendarPath] = update.syncState
+		addedEvents = append(addedEvents, update.added...)
+		updatedEvents = append(updatedEvents, update.updated...)
+		removedEvents = append(removedEvents, update.removed...)
+	}
+	events = distinctEvents(events)
+	c.SortEvents(events)
+	repository.SaveChannelEvents(c.pluginAPI, channelId, events)
+	repository.SaveChannelEventsWindow(c.pluginAPI, channelId, dto.TimeSlot{StartTime: start, EndTime: end})
+	repository.SaveChannelSyncStates(c.pluginAPI, channelId, syncStates)
+	repository.SaveChannelLastUpdate(c.pluginAPI, channelId, now)
+	return addedEvents, updatedEvents, removedEvents
+}
+
+// GetChannelCacheDateTimes returns window of cached channel events from the start of yesterday to the end of the next week
+func GetChannelCacheDateTimes(settings *dto.ChannelSettings) (time.Time, time.Time) {
+	now := time.Now().In(settings.GetLocation())
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	return start.AddDate(0, 0, -conf.EventCachePastDays), end.AddDate(0, 0, conf.EventCacheFutureDays)
+}
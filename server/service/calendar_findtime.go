@@ -0,0 +1,117 @@
+package service
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"time"
+)
+
+const slotStep = 15 * time.Minute
+
+// participantSchedule keeps busy time of participant and his working hours in his location
+type participantSchedule struct {
+	location     *time.Location
+	workingHours *dto.WorkingHours
+	intervals    []dto.BusyInterval
+}
+
+// FindFreeSlots returns first free slots common for all users in [start, end].
+// Slots are searched only in working hours of every user in his timezone
+func (c *Calendar) FindFreeSlots(userIds []string, duration time.Duration, start time.Time, end time.Time) ([]dto.TimeSlot, error) {
+
+	var schedules []participantSchedule
+	for _, userId := range userIds {
+		userSettings := repository.GetSettings(c.pluginAPI, userId)
+		if userSettings == nil {
+			return nil, conf.NewError(conf.MsgCalendarNotConnectedError)
+		}
+		intervals, err := c.LoadBusyIntervals(userId, start, end)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, participantSchedule{
+			location:     userSettings.GetUserLocation(),
+			workingHours: userSettings.GetWorkingHours(),
+			intervals:    intervals,
+		})
+	}
+
+	limit := c.findTimeSlotsLimit
+	if limit <= 0 {
+		limit = conf.FindTimeSlotsLimit
+	}
+	var slots []dto.TimeSlot
+	slotStart := start.Truncate(slotStep)
+	if slotStart.Before(start) {
+		slotStart = slotStart.Add(slotStep)
+	}
+	for ; !slotStart.Add(duration).After(end) && len(slots) < limit; slotStart = slotStart.Add(slotStep) {
+		slot := dto.TimeSlot{StartTime: slotStart, EndTime: slotStart.Add(duration)}
+		if isSlotFree(slot, schedules) {
+			slots = append(slots, slot)
+			slotStart = slot.EndTime.Add(-slotStep)
+		}
+	}
+	return slots, nil
+}
+
+func isSlotFree(slot dto.TimeSlot, schedules []participantSchedule) bool {
+	for _, schedule := range schedules {
+		if !schedule.workingHours.Contains(slot, schedule.location) {
+			return false
+		}
+		for _, interval := range schedule.intervals {
+			if interval.StartTime.Before(slot.EndTime) && interval.EndTime.After(slot.StartTime) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CreateMeeting puts event with name into the first calendar of organizer and invites other participants
+func (c *Calendar) CreateMeeting(organizerId string, participantIds []string, name string, slot dto.TimeSlot) (*dto.Event, error) {
+	userSettings := repository.GetSettings(c.pluginAPI, organizerId)
+	if userSettings == nil || len(userSettings.Calendars) == 0 {
+		return nil, conf.NewError(conf.MsgNotConnectedMessage)
+	}
+	location := userSettings.GetUserLocation()
+	event := dto.NewEvent(
+		model.NewId(),
+		"",
+		name,
+		"",
+		"",
+		userSettings.TimeZone,
+		slot.StartTime.In(location),
+		slot.EndTime.In(location),
+		false,
+		time.Now().UTC(),
+	)
+	for _, participantId := range participantIds {
+		if participantId == organizerId {
+			continue
+		}
+		email := c.getAccountEmail(participantId)
+		if email == "" {
+			continue
+		}
+		attendee := dto.Attendee{
+			Email:    email,
+			Role:     "REQ-PARTICIPANT",
+			PartStat: dto.PartStatNeedsAction,
+		}
+		if user, err := c.pluginAPI.GetUser(participantId); err == nil {
+			attendee.Name = user.GetFullName()
+		}
+		event.Attendees = append(event.Attendees, attendee)
+	}
+	event.CalendarPath = userSettings.Calendars[0].Path
+	event.CalendarName = userSettings.Calendars[0].GetDisplayName()
+	if err := c.CreateEvent(organizerId, *event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"github.com/pkg/errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// https://tools.ietf.org/html/rfc4791#section-7.10
+	freeBusyQueryBodyFormat = `<c:free-busy-query xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<c:time-range start="%s" end="%s"/></c:free-busy-query>`
+	freeBusyTimeFormat = "20060102T150405Z"
+)
+
+// LoadBusyIntervals returns merged busy intervals of user in [start, end].
+// Event titles are added only if user allowed to share them.
+// All day events don't make user busy in both free busy and events paths, timed events do even if they last for days
+func (c *Calendar) LoadBusyIntervals(userId string, start time.Time, end time.Time) ([]dto.BusyInterval, error) {
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
+	if userSettings == nil || len(userSettings.Calendars) == 0 {
+		return nil, conf.NewError(conf.MsgCalendarNotConnectedError)
+	}
+	if !userSettings.ShareEventTitles {
+		intervals, err := c.queryFreeBusy(userId, userSettings.Calendars, start, end)
+		if err == nil {
+			intervals = skipAllDayIntervals(intervals, userSettings.GetUserLocation())
+			return mergeBusyIntervals(intervals, start, end), nil
+		}
+		c.logger.LogWarn("Can't query free busy, fallback to events", &userId, err)
+	}
+
+	events, err := c.GetEvents(userId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var intervals []dto.BusyInterval
+	for _, event := range events {
+		if event.AllDay || event.IsDeclined() {
+			continue
+		}
+		interval := dto.BusyInterval{
+			StartTime: event.StartTime,
+			EndTime:   event.EndTime,
+		}
+		if userSettings.ShareEventTitles {
+			interval.Names = []string{event.Name}
+		}
+		intervals = append(intervals, interval)
+	}
+	return mergeBusyIntervals(intervals, start, end), nil
+}
+
+func (c *Calendar) queryFreeBusy(userId string, calendars []dto.Calendar, start time.Time, end time.Time) ([]dto.BusyInterval, error) {
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf(freeBusyQueryBodyFormat, start.UTC().Format(freeBusyTimeFormat), end.UTC().Format(freeBusyTimeFormat))
+	header := http.Header{}
+	header.Set("Content-Type", "text/xml; charset=\"utf-8\"")
+	header.Set("Depth", "1")
+	var intervals []dto.BusyInterval
+	for _, calendar := range calendars {
+		resp, err := dc.do("REPORT", calendar.Path, header, xml.Header+body)
+		if err != nil {
+			return nil, err
+		}
+		cal, err := ical.NewDecoder(resp.Body).Decode()
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't decode free busy response")
+		}
+		calendarIntervals, err := convertor.FreeBusyToIntervals(cal)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, calendarIntervals...)
+	}
+	return intervals, nil
+}
+
+// skipAllDayIntervals drops periods from midnight to midnight of user location,
+// free busy response doesn't tell which periods are all day events
+func skipAllDayIntervals(intervals []dto.BusyInterval, location *time.Location) []dto.BusyInterval {
+	var timed []dto.BusyInterval
+	for _, interval := range intervals {
+		if isMidnight(interval.StartTime.In(location)) && isMidnight(interval.EndTime.In(location)) &&
+			interval.EndTime.After(interval.StartTime) {
+			continue
+		}
+		timed = append(timed, interval)
+	}
+	return timed
+}
+
+func isMidnight(dt time.Time) bool {
+	return dt.Hour() == 0 && dt.Minute() == 0 && dt.Second() == 0
+}
+
+// mergeBusyIntervals joins overlapping intervals and cuts them by [start, end], so only continuous busy time is shown
+func mergeBusyIntervals(intervals []dto.BusyInterval, start time.Time, end time.Time) []dto.BusyInterval {
+	for i := range intervals {
+		if intervals[i].StartTime.Before(start) {
+			intervals[i].StartTime = start
+		}
+		if intervals[i].EndTime.After(end) {
+			intervals[i].EndTime = end
+		}
+	}
+	sort.SliceStable(intervals, func(i, j int) bool {
+		return intervals[i].StartTime.Before(intervals[j].StartTime)
+	})
+	var merged []dto.BusyInterval
+	for _, interval := range intervals {
+		if !interval.EndTime.After(interval.StartTime) {
+			continue
+		}
+		last := len(merged) - 1
+		if last >= 0 && !interval.StartTime.After(merged[last].EndTime) {
+			if interval.EndTime.After(merged[last].EndTime) {
+				merged[last].EndTime = interval.EndTime
+			}
+			merged[last].Names = append(merged[last].Names, interval.Names...)
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"time"
+)
+
+// RespondToEvent saves participation status of user for event occurrence on server.
+// Calendar object is put only if it wasn't changed after reading, so concurrent edits aren't lost
+func (c *Calendar) RespondToEvent(userId string, objectPath string, recurrenceId string, partStat string) error {
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendar", &userId, err)
+		return conf.NewError(conf.MsgCalendarClientError)
+	}
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get WebDAV client for calendar", &userId, err)
+		return conf.NewError(conf.MsgCalendarClientError)
+	}
+	calendarObject, err := client.GetCalendarObject(objectPath)
+	if err != nil {
+		c.logger.LogError("Can't get event "+objectPath, &userId, err)
+		return conf.NewError(conf.MsgGetEventError)
+	}
+
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
+	if userSettings == nil {
+		return conf.NewError(conf.MsgNotConnectedMessage)
+	}
+	location := userSettings.GetUserLocation()
+	modifiedTime := time.Now().UTC().Truncate(time.Second)
+	changedRecurrenceId, err := convertor.SetAttendeePartStat(
+		calendarObject.Data,
+		recurrenceId,
+		c.getUserEmails(userId),
+		partStat,
+		modifiedTime,
+		location,
+	)
+	if err != nil {
+		c.logger.LogWarn("Can't change participation status in event "+objectPath, &userId, err)
+		return err
+	}
+
+	err = dc.putCalendarObjectIfMatch(objectPath, calendarObject.Data, calendarObject.ETag)
+	if isPreconditionFailed(err) {
+		return conf.NewError(conf.MsgEventChangedError)
+	}
+	if err != nil {
+		c.logger.LogError("Can't save event "+objectPath, &userId, err)
+		return conf.NewError(conf.MsgSaveEventError)
+	}
+
+	c.updateCachedPartStat(userId, objectPath, changedRecurrenceId, partStat, modifiedTime)
+	return nil
+}
+
+// updateCachedPartStat keeps cached events in sync with response, so it isn't reported as event update
+func (c *Calendar) updateCachedPartStat(
+	userId string,
+	objectPath string,
+	recurrenceId string,
+	partStat string,
+	modifiedTime time.Time) {
+
+	events := repository.GetEvents(c.pluginAPI, userId)
+	for i := range events {
+		if events[i].ObjectPath != objectPath {
+			continue
+		}
+		if recurrenceId != "" && events[i].RecurrenceId != recurrenceId {
+			continue
+		}
+		events[i].UserPartStat = partStat
+		events[i].LastModifiedTime = modifiedTime.In(events[i].LastModifiedTime.Location())
+	}
+	repository.SaveEvents(c.pluginAPI, userId, events)
+}
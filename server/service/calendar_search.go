@@ -0,0 +1,30 @@
+package service
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"sort"
+	"time"
+)
+
+// SearchEvents loads events of [start, end] matching query, found events are sorted by date
+func (c *Calendar) SearchEvents(userId string, query string, start time.Time, end time.Time) ([]dto.Event, error) {
+	events, err := c.GetEvents(userId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	normalizedQuery := util.NormalizeSearchText(query)
+	var found []dto.Event
+	for _, event := range events {
+		if event.Matches(normalizedQuery) {
+			found = append(found, event)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].StartTime.Equal(found[j].StartTime) {
+			return found[i].StartTime.Before(found[j].StartTime)
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found, nil
+}
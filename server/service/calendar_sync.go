@@ -0,0 +1,385 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lugamuga/go-webdav/caldav"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	syncStatePropfindBody = `<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/">` +
+		`<d:prop><cs:getctag/><d:sync-token/></d:prop></d:propfind>`
+	// https://tools.ietf.org/html/rfc6578#section-3.2
+	syncCollectionBodyFormat = `<d:sync-collection xmlns:d="DAV:"><d:sync-token>%s</d:sync-token>` +
+		`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`
+	objectsPropfindBody = `<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/></d:prop></d:propfind>`
+)
+
+// calendarChanges keeps events of calendar objects changed since the last synchronization
+type calendarChanges struct {
+	// reloaded is true when all events of calendar were loaded because changed objects are unknown
+	reloaded           bool
+	changedObjectPaths map[string]bool
+	deletedObjectPaths map[string]bool
+	// objects are changed calendar objects, they are kept to find events moved out of window
+	objects []caldav.CalendarObject
+	events  []dto.Event
+}
+
+// calendarSource keeps clients of user who reads calendar and sync state of calendar at the last update.
+// Participation status of user is resolved by userEmails, it's left empty if they aren't set
+type calendarSource struct {
+	userId     string
+	client     *caldav.Client
+	dc         *davClient
+	calendar   dto.Calendar
+	syncState  dto.SyncState
+	userEmails []string
+}
+
+// eventsCache keeps events cached at the last update, eventById includes events of days loaded into window
+type eventsCache struct {
+	events    []dto.Event
+	eventById map[string]dto.Event
+	window    *dto.TimeSlot
+	// outdated cache can't be shifted to the current window, so all calendars are reloaded
+	outdated   bool
+	lastUpdate time.Time
+}
+
+// calendarUpdate keeps actual events of calendar in window and events changed since the last update
+type calendarUpdate struct {
+	events    []dto.Event
+	added     []dto.Event
+	updated   []dto.Event
+	removed   []dto.Event
+	syncState dto.SyncState
+}
+
+func newEventsCache(events []dto.Event, window *dto.TimeSlot, outdated bool, lastUpdate time.Time) *eventsCache {
+	return &eventsCache{
+		events:     events,
+		eventById:  convertor.SliceEventToMapByOccurrenceId(events),
+		window:     window,
+		outdated:   outdated || window == nil,
+		lastUpdate: lastUpdate,
+	}
+}
+
+// updateCalendar patches cached events of calendar by objects changed since the last update and finds changes
+// of events which aren't started yet. If changes can't be loaded, error is returned with cached events of calendar
+func (c *Calendar) updateCalendar(
+	source calendarSource,
+	cache *eventsCache,
+	now time.Time,
+	start time.Time,
+	end time.Time) (*calendarUpdate, error) {
+
+	calendar := source.calendar
+	reload := cache.outdated
+	calendarEvents := filterEventsByRange(filterEventsByCalendar(cache.events, calendar.Path), start, end)
+	if !reload && end.After(cache.window.EndTime) {
+		// Days which came into the window are loaded entirely and aren't reported as added
+		newDayEvents, err := c.loadCalendarEvents(source.client, calendar, cache.window.EndTime, end)
+		if err != nil {
+			c.logger.LogWarn("Can't load new days for calendar "+calendar.Path, &source.userId, err)
+			reload = true
+		} else {
+			resolveUserPartStat(newDayEvents, source.userEmails)
+			calendarEvents = distinctEvents(append(calendarEvents, newDayEvents...))
+			for _, event := range newDayEvents {
+				cache.eventById[event.GetOccurrenceId()] = event
+			}
+		}
+	}
+	var changes *calendarChanges
+	var syncState dto.SyncState
+	var err error
+	if reload {
+		changes, syncState, err = c.reloadCalendar(source.client, source.dc, calendar, start, end)
+	} else {
+		changes, syncState, err = c.loadCalendarChanges(source.client, source.dc, calendar, source.syncState, start, end)
+	}
+	if err != nil {
+		update := &calendarUpdate{syncState: source.syncState}
+		if !reload {
+			update.events = calendarEvents
+		}
+		return update, err
+	}
+	update := &calendarUpdate{syncState: syncState}
+	resolveUserPartStat(changes.events, source.userEmails)
+	loadedEventById := convertor.SliceEventToMapByOccurrenceId(changes.events)
+	var missingEvents []dto.Event
+	for _, event := range calendarEvents {
+		if !changes.reloaded && !changes.changedObjectPaths[event.ObjectPath] {
+			update.events = append(update.events, event)
+			continue
+		}
+		// Events which aren't loaded anymore are dropped from cache, the reason is found below
+		if _, ok := loadedEventById[event.GetOccurrenceId()]; !ok && !cache.outdated && event.StartTime.After(now) {
+			missingEvents = append(missingEvents, event)
+		}
+	}
+	if len(missingEvents) > 0 {
+		removed, moved, err := c.resolveMissingEvents(source.client, source.dc, calendar, changes, missingEvents, start)
+		if err != nil {
+			c.logger.LogWarn("Can't check missing events of calendar "+calendar.Path, &source.userId, err)
+		}
+		resolveUserPartStat(moved, source.userEmails)
+		update.removed = removed
+		// Events moved out of window aren't cached, but their posts are updated
+		update.updated = moved
+	}
+	for _, event := range changes.events {
+		update.events = append(update.events, event)
+		if !event.StartTime.After(now) {
+			continue
+		}
+		existingEvent, ok := cache.eventById[event.GetOccurrenceId()]
+		if changes.reloaded && !event.LastModifiedTime.After(cache.lastUpdate) {
+			continue
+		}
+		if !ok {
+			update.added = append(update.added, event)
+		} else if !existingEvent.LastModifiedTime.Equal(event.LastModifiedTime) {
+			update.updated = append(update.updated, event)
+		}
+	}
+	return update, nil
+}
+
+// loadSyncStates returns current sync markers of all user calendars by calendar path
+func (c *Calendar) loadSyncStates(userId string) map[string]dto.SyncState {
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
+	syncStates := make(map[string]dto.SyncState)
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get WebDAV client for calendars", &userId, err)
+		return syncStates
+	}
+	for _, calendar := range userSettings.Calendars {
+		syncState, err := c.loadSyncState(dc, calendar.Path)
+		if err != nil {
+			c.logger.LogWarn("Can't get sync state for calendar "+calendar.Path, &userId, err)
+			continue
+		}
+		syncStates[calendar.Path] = syncState
+	}
+	return syncStates
+}
+
+// loadCalendarChanges uses sync-collection report if server gave sync token before, otherwise compares getctag.
+// All calendar events are reloaded only if getctag was changed or isn't supported
+func (c *Calendar) loadCalendarChanges(
+	client *caldav.Client,
+	dc *davClient,
+	calendar dto.Calendar,
+	syncState dto.SyncState,
+	start time.Time,
+	end time.Time) (*calendarChanges, dto.SyncState, error) {
+
+	if syncState.SyncToken != "" {
+		changedPaths, deletedPaths, syncToken, err := c.syncCollection(dc, calendar.Path, syncState.SyncToken)
+		if err == nil {
+			changes := &calendarChanges{
+				changedObjectPaths: make(map[string]bool),
+				deletedObjectPaths: make(map[string]bool),
+			}
+			for _, path := range changedPaths {
+				changes.changedObjectPaths[path] = true
+			}
+			for _, path := range deletedPaths {
+				changes.changedObjectPaths[path] = true
+				changes.deletedObjectPaths[path] = true
+			}
+			if len(changedPaths) > 0 {
+				changes.objects, err = c.multiGetCalendarObjects(client, calendar, changedPaths)
+				if err != nil {
+					return nil, syncState, err
+				}
+				changes.events, err = c.toCalendarEvents(changes.objects, calendar, start, end)
+				if err != nil {
+					return nil, syncState, err
+				}
+			}
+			syncState.SyncToken = syncToken
+			return changes, syncState, nil
+		}
+		c.logger.LogWarn("Can't sync collection "+calendar.Path+", fallback to getctag", nil, err)
+	}
+
+	actualSyncState, err := c.loadSyncState(dc, calendar.Path)
+	if err != nil {
+		c.logger.LogWarn("Can't get sync state for calendar "+calendar.Path, nil, err)
+	}
+	if syncState.CTag != "" && syncState.CTag == actualSyncState.CTag {
+		return &calendarChanges{}, actualSyncState, nil
+	}
+	events, err := c.loadCalendarEvents(client, calendar, start, end)
+	if err != nil {
+		return nil, syncState, err
+	}
+	return &calendarChanges{reloaded: true, events: events}, actualSyncState, nil
+}
+
+func (c *Calendar) reloadCalendar(
+	client *caldav.Client,
+	dc *davClient,
+	calendar dto.Calendar,
+	start time.Time,
+	end time.Time) (*calendarChanges, dto.SyncState, error) {
+
+	// Sync state is taken before loading events, so changes made in between will be loaded next time
+	syncState, err := c.loadSyncState(dc, calendar.Path)
+	if err != nil {
+		c.logger.LogWarn("Can't get sync state for calendar "+calendar.Path, nil, err)
+	}
+	events, err := c.loadCalendarEvents(client, calendar, start, end)
+	if err != nil {
+		return nil, syncState, err
+	}
+	return &calendarChanges{reloaded: true, events: events}, syncState, nil
+}
+
+func (c *Calendar) loadSyncState(dc *davClient, calendarPath string) (dto.SyncState, error) {
+	ms, err := dc.doMultiStatus("PROPFIND", calendarPath, "0", syncStatePropfindBody)
+	if err != nil {
+		return dto.SyncState{}, err
+	}
+	if len(ms.Responses) == 0 {
+		return dto.SyncState{}, errors.New("Empty PROPFIND response for " + calendarPath)
+	}
+	prop := ms.Responses[0].getProp()
+	return dto.SyncState{
+		SyncToken: prop.SyncToken,
+		CTag:      prop.CTag,
+	}, nil
+}
+
+// syncCollection returns paths of changed and deleted calendar objects and new sync token
+func (c *Calendar) syncCollection(dc *davClient, calendarPath string, syncToken string) ([]string, []string, string, error) {
+	body := fmt.Sprintf(syncCollectionBodyFormat, escapeXML(syncToken))
+	ms, err := dc.doMultiStatus("REPORT", calendarPath, "1", body)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if ms.SyncToken == "" {
+		return nil, nil, "", errors.New("Sync token is absent in sync-collection response")
+	}
+	var changedPaths []string
+	var deletedPaths []string
+	for _, resp := range ms.Responses {
+		path := resp.getPath()
+		if path == calendarPath {
+			continue
+		}
+		if resp.isNotFound() {
+			deletedPaths = append(deletedPaths, path)
+		} else {
+			changedPaths = append(changedPaths, path)
+		}
+	}
+	return changedPaths, deletedPaths, ms.SyncToken, nil
+}
+
+// resolveMissingEvents finds out why cached events of changed objects weren't loaded for window.
+// Events of deleted objects and cancelled or excluded occurrences are removed, events rescheduled
+// out of window are returned as moved with their new time
+func (c *Calendar) resolveMissingEvents(
+	client *caldav.Client,
+	dc *davClient,
+	calendar dto.Calendar,
+	changes *calendarChanges,
+	events []dto.Event,
+	start time.Time) ([]dto.Event, []dto.Event, error) {
+
+	deletedPaths := changes.deletedObjectPaths
+	objects := changes.objects
+	if changes.reloaded {
+		// Changed objects are unknown after reload, so objects of missing events are requested
+		existingPaths, err := c.listObjectPaths(dc, calendar.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		deletedPaths = make(map[string]bool)
+		requestedPaths := make(map[string]bool)
+		var paths []string
+		for _, event := range events {
+			if !existingPaths[event.ObjectPath] {
+				deletedPaths[event.ObjectPath] = true
+			} else if !requestedPaths[event.ObjectPath] {
+				requestedPaths[event.ObjectPath] = true
+				paths = append(paths, event.ObjectPath)
+			}
+		}
+		if len(paths) > 0 {
+			objects, err = c.multiGetCalendarObjects(client, calendar, paths)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	searchStart := start.AddDate(0, 0, -conf.MovedEventSearchDays)
+	searchEnd := start.AddDate(0, 0, conf.MovedEventSearchDays)
+	foundEvents, err := c.toCalendarEvents(objects, calendar, searchStart, searchEnd)
+	if err != nil {
+		return nil, nil, err
+	}
+	foundEventById := convertor.SliceEventToMapByOccurrenceId(foundEvents)
+	var removedEvents []dto.Event
+	var movedEvents []dto.Event
+	for _, event := range events {
+		if deletedPaths[event.ObjectPath] {
+			removedEvents = append(removedEvents, event)
+			continue
+		}
+		if moved, ok := foundEventById[event.GetOccurrenceId()]; ok {
+			movedEvents = append(movedEvents, moved)
+			continue
+		}
+		// Cancelled events and occurrences excluded by EXDATE aren't converted, so they aren't found
+		removedEvents = append(removedEvents, event)
+	}
+	return removedEvents, movedEvents, nil
+}
+
+// listObjectPaths returns paths of all objects in calendar
+func (c *Calendar) listObjectPaths(dc *davClient, calendarPath string) (map[string]bool, error) {
+	ms, err := dc.doMultiStatus("PROPFIND", calendarPath, "1", objectsPropfindBody)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]bool)
+	for _, resp := range ms.Responses {
+		if path := resp.getPath(); path != calendarPath && !resp.isNotFound() {
+			paths[path] = true
+		}
+	}
+	return paths, nil
+}
+
+func (c *Calendar) multiGetCalendarObjects(
+	client *caldav.Client,
+	calendar dto.Calendar,
+	paths []string) ([]caldav.CalendarObject, error) {
+
+	multiGet := &caldav.CalendarMultiGet{
+		Paths: paths,
+		CompRequest: caldav.CalendarCompRequest{
+			Name:     "VCALENDAR",
+			AllProps: true,
+			AllComps: true,
+		},
+	}
+	calendarObjects, err := client.MultiGetCalendar(calendar.Path, multiGet)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't get changed events from calendar")
+	}
+	return calendarObjects, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"github.com/lugamuga/go-webdav/caldav"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+// LoadTasks loads open tasks with due date from selected calendars sorted by due time
+func (c *Calendar) LoadTasks(userId string) ([]dto.Task, error) {
+	var tasks []dto.Task
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
+	if userSettings == nil {
+		return tasks, conf.NewError(conf.MsgNotConnectedMessage)
+	}
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendars", &userId, err)
+		return tasks, conf.NewError(conf.MsgCalendarClientError)
+	}
+	failedCalendars := 0
+	for _, calendar := range userSettings.Calendars {
+		calendarTasks, err := c.loadCalendarTasks(client, calendar, userSettings.GetUserLocation())
+		if err != nil {
+			c.logger.LogWarn("Can't load tasks for calendar "+calendar.Path, &userId, err)
+			failedCalendars++
+			continue
+		}
+		tasks = append(tasks, calendarTasks...)
+	}
+	sortTasks(tasks)
+	if failedCalendars > 0 && failedCalendars == len(userSettings.Calendars) {
+		return tasks, conf.NewError(conf.MsgGetTasksError)
+	}
+	return tasks, nil
+}
+
+// refreshTasks caches tasks for digest and due reminders, cache is kept if calendars aren't available.
+// Tasks of calendar are loaded only if it was changed since the last refresh
+func (c *Calendar) refreshTasks(userId string) {
+	userSettings := repository.GetSettings(c.pluginAPI, userId)
+	if userSettings == nil {
+		return
+	}
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendars", &userId, err)
+		return
+	}
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get WebDAV client for calendars", &userId, err)
+		return
+	}
+	syncStates := repository.GetTaskSyncStates(c.pluginAPI, userId)
+	cachedTasks := repository.GetTasks(c.pluginAPI, userId)
+	actualSyncStates := make(map[string]dto.SyncState)
+	var tasks []dto.Task
+	for _, calendar := range userSettings.Calendars {
+		var calendarTasks []dto.Task
+		for _, task := range cachedTasks {
+			if task.CalendarPath == calendar.Path {
+				calendarTasks = append(calendarTasks, task)
+			}
+		}
+		// calendar without sync state has empty getctag, so its tasks are loaded
+		syncState, ok := syncStates[calendar.Path]
+		loadedTasks, actualSyncState, err := c.loadCalendarTaskChanges(
+			client, dc, calendar, syncState, calendarTasks, userSettings.GetUserLocation())
+		if err != nil {
+			c.logger.LogWarn("Can't load tasks for calendar "+calendar.Path, &userId, err)
+			tasks = append(tasks, calendarTasks...)
+			if ok {
+				actualSyncStates[calendar.Path] = syncState
+			}
+			continue
+		}
+		tasks = append(tasks, loadedTasks...)
+		actualSyncStates[calendar.Path] = actualSyncState
+	}
+	sortTasks(tasks)
+	repository.SaveTasks(c.pluginAPI, userId, tasks)
+	repository.SaveTaskSyncStates(c.pluginAPI, userId, actualSyncStates)
+}
+
+// loadCalendarTaskChanges applies objects changed since sync token to cached tasks of calendar.
+// Without sync token tasks are queried only if getctag was changed or isn't supported
+func (c *Calendar) loadCalendarTaskChanges(
+	client *caldav.Client,
+	dc *davClient,
+	calendar dto.Calendar,
+	syncState dto.SyncState,
+	cachedTasks []dto.Task,
+	location *time.Location) ([]dto.Task, dto.SyncState, error) {
+
+	if syncState.SyncToken != "" {
+		changedPaths, deletedPaths, syncToken, err := c.syncCollection(dc, calendar.Path, syncState.SyncToken)
+		if err == nil {
+			removedPaths := make(map[string]bool)
+			for _, path := range append(changedPaths, deletedPaths...) {
+				removedPaths[path] = true
+			}
+			var tasks []dto.Task
+			for _, task := range cachedTasks {
+				if !removedPaths[task.ObjectPath] {
+					tasks = append(tasks, task)
+				}
+			}
+			if len(changedPaths) > 0 {
+				calendarObjects, err := c.multiGetCalendarObjects(client, calendar, changedPaths)
+				if err != nil {
+					return nil, syncState, err
+				}
+				changedTasks, err := toCalendarTasks(calendarObjects, calendar, location)
+				if err != nil {
+					return nil, syncState, err
+				}
+				tasks = append(tasks, changedTasks...)
+			}
+			syncState.SyncToken = syncToken
+			return tasks, syncState, nil
+		}
+		c.logger.LogWarn("Can't sync collection "+calendar.Path+", fallback to getctag", nil, err)
+	}
+
+	actualSyncState, err := c.loadSyncState(dc, calendar.Path)
+	if err != nil {
+		c.logger.LogWarn("Can't get sync state for calendar "+calendar.Path, nil, err)
+	}
+	if syncState.CTag != "" && syncState.CTag == actualSyncState.CTag {
+		return cachedTasks, actualSyncState, nil
+	}
+	tasks, err := c.loadCalendarTasks(client, calendar, location)
+	if err != nil {
+		return nil, syncState, err
+	}
+	return tasks, actualSyncState, nil
+}
+
+func (c *Calendar) loadCalendarTasks(client *caldav.Client, calendar dto.Calendar, location *time.Location) ([]dto.Task, error) {
+	calendarObjects, err := c.queryCalendarTasks(client, calendar.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't get tasks from calendar")
+	}
+	return toCalendarTasks(calendarObjects, calendar, location)
+}
+
+func toCalendarTasks(calendarObjects []caldav.CalendarObject, calendar dto.Calendar, location *time.Location) ([]dto.Task, error) {
+	tasks, err := convertor.CalendarObjectToTaskArray(calendarObjects, location)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse tasks from calendar")
+	}
+	for i := range tasks {
+		tasks[i].CalendarPath = calendar.Path
+		tasks[i].CalendarName = calendar.GetDisplayName()
+	}
+	return tasks, nil
+}
+
+func sortTasks(tasks []dto.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].DueTime.Equal(tasks[j].DueTime) {
+			return tasks[i].DueTime.Before(tasks[j].DueTime)
+		}
+		return tasks[i].Name < tasks[j].Name
+	})
+}
+
+// CompleteTask sets status of task to completed on server if it wasn't changed after reading
+func (c *Calendar) CompleteTask(userId string, objectPath string, taskId string) error {
+	client, err := c.getClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get client for calendar", &userId, err)
+		return conf.NewError(conf.MsgCalendarClientError)
+	}
+	dc, err := c.getDavClient(userId)
+	if err != nil {
+		c.logger.LogError("Can't get WebDAV client for calendar", &userId, err)
+		return conf.NewError(conf.MsgCalendarClientError)
+	}
+	calendarObject, err := client.GetCalendarObject(objectPath)
+	if err != nil {
+		c.logger.LogError("Can't get task "+objectPath, &userId, err)
+		return conf.NewError(conf.MsgGetTaskError)
+	}
+	if err := convertor.SetTaskCompleted(calendarObject.Data, taskId, time.Now().UTC().Truncate(time.Second)); err != nil {
+		c.logger.LogWarn("Can't complete task "+objectPath, &userId, err)
+		return err
+	}
+
+	err = dc.putCalendarObjectIfMatch(objectPath, calendarObject.Data, calendarObject.ETag)
+	if isPreconditionFailed(err) {
+		return conf.NewError(conf.MsgTaskChangedError)
+	}
+	if err != nil {
+		c.logger.LogError("Can't save task "+objectPath, &userId, err)
+		return conf.NewError(conf.MsgSaveTaskError)
+	}
+
+	var tasks []dto.Task
+	for _, task := range repository.GetTasks(c.pluginAPI, userId) {
+		if task.ObjectPath != objectPath || task.Id != taskId {
+			tasks = append(tasks, task)
+		}
+	}
+	repository.SaveTasks(c.pluginAPI, userId, tasks)
+	return nil
+}
+
+func (c *Calendar) queryCalendarTasks(client *caldav.Client, calendarPath string) ([]caldav.CalendarObject, error) {
+	query := &caldav.CalendarQuery{
+		CompFilter: caldav.CompFilter{
+			Name:  "VCALENDAR",
+			Comps: []caldav.CompFilter{{Name: "VTODO"}},
+		},
+	}
+	return client.QueryCalendar(calendarPath, query)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+	"github.com/pkg/errors"
+)
+
+// Channel posts daily schedule, event updates and reminders at start of calendars subscribed by channel
+type Channel struct {
+	logger    *util.Logger
+	pluginAPI plugin.API
+	sender    *Sender
+	calendar  *Calendar
+	workspace *Workspace
+}
+
+func NewChannelService(
+	logger *util.Logger,
+	plugin plugin.API,
+	sender *Sender,
+	calendar *Calendar,
+	workspace *Workspace) *Channel {
+	return &Channel{
+		logger:    logger,
+		pluginAPI: plugin,
+		sender:    sender,
+		calendar:  calendar,
+		workspace: workspace,
+	}
+}
+
+// Subscribe links calendar of user to channel and loads its events, bot joins channel to post there.
+// The first subscriber defines timezone, digest time and language of channel
+func (c *Channel) Subscribe(userId string, channelId string, calendar dto.Calendar) error {
+	if err := c.sender.JoinChannel(channelId); err != nil {
+		return errors.Wrap(err, "Can't add bot to channel")
+	}
+	settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+	if settings == nil {
+		userSettings := repository.GetSettings(c.pluginAPI, userId)
+		if userSettings == nil {
+			userSettings = dto.DefaultSettings()
+		}
+		dailyNotifyTime := userSettings.DailyNotifyTime
+		if dailyNotifyTime == nil {
+			dailyNotifyTime = dto.DefaultSettings().DailyNotifyTime
+		}
+		settings = &dto.ChannelSettings{
+			TimeZone:        userSettings.TimeZone,
+			DailyNotifyTime: dailyNotifyTime,
+			Language:        c.sender.GetLanguage(userId),
+		}
+	}
+	settings.Subscriptions = append(settings.Subscriptions, dto.ChannelSubscription{
+		UserId:   userId,
+		Calendar: calendar,
+	})
+	repository.SaveChannelSettings(c.pluginAPI, channelId, *settings)
+	c.workspace.AddChannel(channelId)
+	c.LoadChannelCalendar(channelId)
+	return nil
+}
+
+// Unsubscribe unlinks calendar from channel, channel without calendars is removed from workspace.
+// Returns true if channel was removed, so its cron jobs should be stopped
+func (c *Channel) Unsubscribe(channelId string, calendarPath string) bool {
+	settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+	if settings == nil {
+		return false
+	}
+	settings.RemoveSubscriptions(func(subscription dto.ChannelSubscription) bool {
+		return subscription.Calendar.Path == calendarPath
+	})
+	return c.saveOrDeleteChannel(channelId, settings)
+}
+
+// DeleteUserSubscriptions unlinks calendars of user from all channels, as they can't be loaded without credentials.
+// Returns ids of channels removed as they have no calendars left
+func (c *Channel) DeleteUserSubscriptions(userId string) []string {
+	var deletedChannelIds []string
+	for channelId := range c.workspace.GetChannelIds() {
+		settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+		if settings == nil {
+			continue
+		}
+		removed := settings.RemoveSubscriptions(func(subscription dto.ChannelSubscription) bool {
+			return subscription.UserId == userId
+		})
+		if removed && c.saveOrDeleteChannel(channelId, settings) {
+			deletedChannelIds = append(deletedChannelIds, channelId)
+		}
+	}
+	return deletedChannelIds
+}
+
+func (c *Channel) saveOrDeleteChannel(channelId string, settings *dto.ChannelSettings) bool {
+	if len(settings.Subscriptions) == 0 {
+		c.workspace.DeleteChannel(channelId)
+		return true
+	}
+	repository.SaveChannelSettings(c.pluginAPI, channelId, *settings)
+	c.LoadChannelCalendar(channelId)
+	return false
+}
+
+// LoadChannelCalendar reloads cached events of channel, changes aren't reported
+func (c *Channel) LoadChannelCalendar(channelId string) {
+	settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+	if settings == nil {
+		return
+	}
+	c.calendar.LoadChannelCalendarUpdates(channelId, settings, true)
+}
+
+// ChannelEventsHandler sends daily schedule and reminders at start of events to channel
+func (c *Channel) ChannelEventsHandler(channelId string) {
+	settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+	if settings == nil {
+		return
+	}
+	now := settings.GetNow()
+	events := repository.GetChannelEvents(c.pluginAPI, channelId)
+	for i := range events {
+		events[i].InLocation(now.Location())
+	}
+	if settings.DailyNotifyTime != nil &&
+		util.IsDailyTime(now, settings.DailyNotifyTime.Hour(), settings.DailyNotifyTime.Minute()) {
+		title := conf.GetTodayEventsTitle(settings.Language, now)
+		c.sender.SendChannelDigest(channelId, settings.Language, title, getDayEvents(events, now))
+	}
+	for _, event := range events {
+		if event.IsWholeDay() || !event.StartEquals(now) {
+			continue
+		}
+		c.sender.SendChannelReminder(channelId, settings.Language, conf.GetReminderTitle(settings.Language, 0), event)
+	}
+}
+
+// LoadChannelEventUpdates loads changes of subscribed calendars and sends added, changed and cancelled events.
+// Events of days which came into cache window are reported only if they were modified since the last update
+func (c *Channel) LoadChannelEventUpdates(channelId string) {
+	settings := repository.GetChannelSettings(c.pluginAPI, channelId)
+	if settings == nil {
+		return
+	}
+	isFirstUpdate := repository.GetChannelLastUpdate(c.pluginAPI, channelId) == nil
+	previousEventsById := convertor.SliceEventToMapByOccurrenceId(repository.GetChannelEvents(c.pluginAPI, channelId))
+	addedEvents, updatedEvents, removedEvents := c.calendar.LoadChannelCalendarUpdates(channelId, settings, false)
+	if isFirstUpdate {
+		return
+	}
+	location := settings.GetLocation()
+	for i := range removedEvents {
+		removedEvents[i].InLocation(location)
+	}
+
+	language := settings.Language
+	if len(addedEvents) > 0 {
+		c.sender.SendChannelEventUpdates(channelId, language, conf.T(language, conf.MsgAddedEventsTitle), addedEvents)
+	}
+	if len(updatedEvents) > 0 {
+		title := conf.T(language, conf.MsgUpdatedEventsTitle)
+		c.sender.SendChannelEventChanges(channelId, language, title, updatedEvents, previousEventsById, false)
+	}
+	if len(removedEvents) > 0 {
+		title := conf.T(language, conf.MsgRemovedEventsTitle)
+		c.sender.SendChannelEventChanges(channelId, language, title, removedEvents, previousEventsById, true)
+	}
+}
+
+// IsChannelExist checks channel wasn't deleted, channel is kept if it can't be checked now
+func (c *Channel) IsChannelExist(channelId string) bool {
+	channel, err := c.pluginAPI.GetChannel(channelId)
+	if err == nil && (channel == nil || channel.DeleteAt != 0) {
+		return false
+	}
+	return true
+}
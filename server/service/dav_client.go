@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/emersion/go-ical"
+	"github.com/lugamuga/go-webdav"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// davClient performs raw WebDAV requests which aren't supported by caldav.Client
+type davClient struct {
+	httpClient webdav.HTTPClient
+	endpoint   *url.URL
+}
+
+// davHTTPError keeps status code of unsuccessful WebDAV response
+type davHTTPError struct {
+	Code int
+}
+
+func (e *davHTTPError) Error() string {
+	return fmt.Sprintf("WebDAV request failed with status %d", e.Code)
+}
+
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"DAV: multistatus"`
+	Responses []davResponse `xml:"DAV: response"`
+	SyncToken string        `xml:"DAV: sync-token"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Status    string        `xml:"DAV: status"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Status string  `xml:"DAV: status"`
+	Prop   davProp `xml:"DAV: prop"`
+}
+
+type davProp struct {
+	CTag      string `xml:"http://calendarserver.org/ns/ getctag"`
+	SyncToken string `xml:"DAV: sync-token"`
+	ETag      string `xml:"DAV: getetag"`
+}
+
+func newDavClient(httpClient webdav.HTTPClient, endpoint string) (*davClient, error) {
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &davClient{
+		httpClient: httpClient,
+		endpoint:   endpointUrl,
+	}, nil
+}
+
+func (dc *davClient) resolveHref(path string) string {
+	return dc.endpoint.ResolveReference(&url.URL{Path: path}).String()
+}
+
+func (dc *davClient) do(method string, path string, header http.Header, body string) (*http.Response, error) {
+	req, err := http.NewRequest(method, dc.resolveHref(path), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	resp, err := dc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		_ = resp.Body.Close()
+		return nil, &davHTTPError{Code: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+func (dc *davClient) doMultiStatus(method string, path string, depth string, body string) (*davMultistatus, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/xml; charset=\"utf-8\"")
+	header.Set("Depth", depth)
+	resp, err := dc.do(method, path, header, xml.Header+body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, errors.New("Expected multi-status response but got " + resp.Status)
+	}
+	var ms davMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+	return &ms, nil
+}
+
+// putCalendarObjectIfMatch saves calendar object only if it wasn't changed since etag was received
+func (dc *davClient) putCalendarObjectIfMatch(path string, cal *ical.Calendar, etag string) error {
+	var buf bytes.Buffer
+	if err := ical.NewEncoder(&buf).Encode(cal); err != nil {
+		return err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", ical.MIMEType)
+	if etag != "" {
+		header.Set("If-Match", strconv.Quote(etag))
+	}
+	resp, err := dc.do(http.MethodPut, path, header, buf.String())
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// getProp returns properties from successful propstat of response
+func (r *davResponse) getProp() davProp {
+	for _, propstat := range r.Propstats {
+		if isDavStatusOk(propstat.Status) {
+			return propstat.Prop
+		}
+	}
+	return davProp{}
+}
+
+// getPath returns path of href, href could be absolute URL
+func (r *davResponse) getPath() string {
+	href, err := url.Parse(strings.TrimSpace(r.Href))
+	if err != nil {
+		return r.Href
+	}
+	return href.Path
+}
+
+func (r *davResponse) isNotFound() bool {
+	return strings.Contains(r.Status, " 404 ")
+}
+
+func isPreconditionFailed(err error) bool {
+	httpErr, ok := err.(*davHTTPError)
+	return ok && httpErr.Code == http.StatusPreconditionFailed
+}
+
+func isDavStatusOk(status string) bool {
+	return status == "" || strings.Contains(status, " 200 ")
+}
+
+func escapeXML(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
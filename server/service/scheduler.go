@@ -18,16 +18,18 @@ type Scheduler struct {
 	logger    *util.Logger
 	pluginAPI plugin.API
 	user      *User
+	channel   *Channel
 	workspace *Workspace
 	cron      *cron.Cron
 }
 
-func NewSchedulerService(logger *util.Logger, plugin plugin.API, workspace *Workspace, user *User) *Scheduler {
+func NewSchedulerService(logger *util.Logger, plugin plugin.API, workspace *Workspace, user *User, channel *Channel) *Scheduler {
 	scheduler := &Scheduler{
 		logger:    logger,
 		pluginAPI: plugin,
 		workspace: workspace,
 		user:      user,
+		channel:   channel,
 		cron:      cron.New(),
 	}
 	return scheduler
@@ -39,6 +41,10 @@ func (s *Scheduler) InitCronJobs() {
 		repository.DeleteUserCronJobIds(s.pluginAPI, userId)
 		s.AddCronJobs(userId)
 	}
+	for channelId := range s.workspace.GetChannelIds() {
+		repository.DeleteChannelCronJobIds(s.pluginAPI, channelId)
+		s.AddChannelCronJobs(channelId)
+	}
 	s.cron.Start()
 }
 
@@ -87,11 +93,62 @@ func (s *Scheduler) DeleteCronJobs(userId string) {
 	}
 }
 
+// AddChannelCronJobs schedules digest, reminders and updates of calendars subscribed by channel
+func (s *Scheduler) AddChannelCronJobs(channelId string) {
+	eventCronId, updateCronId := s.getActiveChannelCronJobIds(channelId)
+
+	if eventCronId == nil {
+		eventCronEntryId, eventError := s.cron.AddFunc(UserEventHandlerCronSpec, func() {
+			s.runHandlerOrDeleteChannel(channelId, s.channel.ChannelEventsHandler)
+		})
+		if eventError != nil {
+			s.logger.Warn("Error in create Event CRON for channel "+channelId, nil)
+		} else {
+			repository.SaveChannelEventCronJob(s.pluginAPI, channelId, int(eventCronEntryId))
+		}
+	}
+	if updateCronId == nil {
+		updateCronEntryId, updateError := s.cron.AddFunc(UserEventUpdaterCronSpec, func() {
+			s.runHandlerOrDeleteChannel(channelId, s.channel.LoadChannelEventUpdates)
+		})
+		if updateError != nil {
+			s.logger.Warn("Error in create Update CRON for channel "+channelId, nil)
+		} else {
+			repository.SaveChannelUpdateCronJob(s.pluginAPI, channelId, int(updateCronEntryId))
+		}
+	}
+}
+
+// DeleteChannelCronJobs stops jobs of channel and forgets their ids
+func (s *Scheduler) DeleteChannelCronJobs(channelId string) {
+	eventCronId, updateCronId := repository.GetChannelCronJobIds(s.pluginAPI, channelId)
+	if eventCronId != nil {
+		s.cron.Remove(cron.EntryID(*eventCronId))
+	}
+	if updateCronId != nil {
+		s.cron.Remove(cron.EntryID(*updateCronId))
+	}
+	repository.DeleteChannelCronJobIds(s.pluginAPI, channelId)
+}
+
+// runHandlerOrDeleteChannel stops jobs of channel which was deleted or has no subscriptions anymore
+func (s *Scheduler) runHandlerOrDeleteChannel(channelId string, handler func(string)) {
+	if s.workspace.GetChannelIds()[channelId] && s.channel.IsChannelExist(channelId) {
+		handler(channelId)
+		return
+	}
+	s.DeleteChannelCronJobs(channelId)
+	s.workspace.DeleteChannel(channelId)
+}
+
 func (s *Scheduler) runHandlerOrDeleteUser(userId string, handler func(string)) {
 	if s.user.IsUserExist(userId) {
 		handler(userId)
 	} else {
 		s.DeleteCronJobs(userId)
+		for _, channelId := range s.channel.DeleteUserSubscriptions(userId) {
+			s.DeleteChannelCronJobs(channelId)
+		}
 		s.workspace.DeleteUser(userId)
 	}
 }
@@ -112,3 +169,14 @@ func (s *Scheduler) getActiveCronJobIds(userId string) (*int, *int) {
 	}
 	return eventCronId, updateCronId
 }
+
+func (s *Scheduler) getActiveChannelCronJobIds(channelId string) (*int, *int) {
+	eventCronId, updateCronId := repository.GetChannelCronJobIds(s.pluginAPI, channelId)
+	if eventCronId != nil && s.cron.Entry(cron.EntryID(*eventCronId)).ID == 0 {
+		eventCronId = nil
+	}
+	if updateCronId != nil && s.cron.Entry(cron.EntryID(*updateCronId)).ID == 0 {
+		updateCronId = nil
+	}
+	return eventCronId, updateCronId
+}
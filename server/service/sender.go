@@ -8,12 +8,18 @@ import (
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin"
 	"github.com/tkuchiki/go-timezone"
+	"hash/fnv"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 )
 
+const timeOptionFormat = "15:04"
+
+var calendarColors = []string{"#2389d7", "#3db887", "#ffbc1f", "#ff8800", "#a05cb8", "#d24b4e", "#06d6a0", "#7a5c45"}
+
 type Sender struct {
 	manifestId                string
 	botId                     string
@@ -21,8 +27,10 @@ type Sender struct {
 	pluginAPI                 plugin.API
 	supportedUserCustomStatus bool
 	serverConfig              *model.Config
+	templates                 *MessageTemplates
 	timezoneOptions           []*model.PostActionOptions
 	dailyNotifyTimeOptions    []*model.PostActionOptions
+	eventStartOptions         []*model.PostActionOptions
 }
 
 func NewSenderService(
@@ -31,7 +39,8 @@ func NewSenderService(
 	logger *util.Logger,
 	plugin plugin.API,
 	supportedUserCustomStatus bool,
-	serverConfig *model.Config) *Sender {
+	serverConfig *model.Config,
+	templates *MessageTemplates) *Sender {
 	return &Sender{
 		manifestId:                manifestId,
 		botId:                     botId,
@@ -39,11 +48,45 @@ func NewSenderService(
 		pluginAPI:                 plugin,
 		supportedUserCustomStatus: supportedUserCustomStatus,
 		serverConfig:              serverConfig,
+		templates:                 templates,
 		timezoneOptions:           prepareTimezoneOptions(),
 		dailyNotifyTimeOptions:    prepareDailyNotifyTimeOptions(),
+		eventStartOptions:         prepareEventStartOptions(),
+	}
+}
+
+// GetLanguage picks language of messages by locale of user
+func (s *Sender) GetLanguage(userId string) string {
+	user, appErr := s.pluginAPI.GetUser(userId)
+	if appErr != nil {
+		s.logger.LogWarn("Couldn't get user", &userId, appErr)
+		return util.LanguageEn
+	}
+	return util.GetLanguage(user.Locale)
+}
+
+// GetLanguageOnce returns func which picks language of user on the first call only,
+// so handlers which send nothing don't load user
+func (s *Sender) GetLanguageOnce(userId string) func() string {
+	language := ""
+	return func() string {
+		if language == "" {
+			language = s.GetLanguage(userId)
+		}
+		return language
 	}
 }
 
+// SendEphemeralPost shows message of bot only to user in channel, it works where bot can't write to user by DM
+func (s *Sender) SendEphemeralPost(userId string, channelId string, message string) {
+	post := &model.Post{
+		UserId:    s.botId,
+		ChannelId: channelId,
+		Message:   message,
+	}
+	s.pluginAPI.SendEphemeralPost(userId, post)
+}
+
 func (s *Sender) SendBotDMPost(userId string, message string) {
 	channel, err := s.pluginAPI.GetDirectChannel(userId, s.botId)
 	if err != nil {
@@ -54,24 +97,37 @@ func (s *Sender) SendBotDMPost(userId string, message string) {
 		ChannelId: channel.Id,
 		Message:   message,
 	}
-	err = s.sendPost(post)
+	_, err = s.sendPost(post)
 	if err != nil {
 		s.logger.LogError("Couldn't send direct message to user from bot", &userId, err)
 	}
 }
 
-func (s *Sender) SendWelcomePost(userId string) {
-	message := "#### Welcome to the Mattermost Yandex Calendar Plugin!\n" +
-		"Please type **/calendar help** to understand how to use this plugin. "
+func (s *Sender) SendWelcomePost(userId string, language string) {
+	message := conf.T(language, conf.MsgWelcomeMessage)
+	if tmpl := s.templates.getWelcome(); tmpl != nil {
+		rendered, err := renderTemplate(tmpl, getWelcomeTemplateData())
+		if err != nil {
+			s.logger.LogWarn("Can't render welcome template", &userId, err)
+		} else {
+			message = rendered
+		}
+	}
 	s.SendBotDMPost(userId, message)
 }
 
-func (s *Sender) OpenSettingsDialog(triggerId string, rootId string, calendars []caldav.Calendar, settings *dto.Settings) error {
+func (s *Sender) OpenSettingsDialog(
+	language string,
+	triggerId string,
+	rootId string,
+	calendars []caldav.Calendar,
+	settings *dto.Settings) error {
+
 	siteURL := *s.serverConfig.ServiceSettings.SiteURL
 	dialog := model.OpenDialogRequest{
 		TriggerId: triggerId,
 		URL:       conf.ResolveUrlByPlugin(strings.ToLower(s.manifestId), conf.CalendarSettings),
-		Dialog:    s.getSettingsDialog(siteURL, rootId, calendars, settings),
+		Dialog:    s.getSettingsDialog(siteURL, rootId, language, calendars, settings),
 	}
 
 	if appErr := s.pluginAPI.OpenInteractiveDialog(dialog); appErr != nil {
@@ -81,27 +137,31 @@ func (s *Sender) OpenSettingsDialog(triggerId string, rootId string, calendars [
 	return nil
 }
 
-func (s *Sender) getSettingsDialog(siteURL string, rootId string, calendars []caldav.Calendar, settings *dto.Settings) model.Dialog {
-	var calendarOptions []*model.PostActionOptions
-	for _, c := range calendars {
-		calendarOptions = append(calendarOptions, &model.PostActionOptions{
-			Text:  c.Name,
-			Value: c.Path,
+func (s *Sender) getSettingsDialog(
+	siteURL string,
+	rootId string,
+	language string,
+	calendars []caldav.Calendar,
+	settings *dto.Settings) model.Dialog {
+
+	var dialogElements []model.DialogElement
+	for i, c := range calendars {
+		selected := settings.HasCalendar(c.Path)
+		if len(settings.Calendars) == 0 && i == 0 {
+			selected = true
+		}
+		dialogElements = append(dialogElements, model.DialogElement{
+			Name:        conf.GetCalendarDialogOption(c.Path),
+			DisplayName: conf.T(language, conf.MsgCalendarDialogElement) + c.Name,
+			Type:        "bool",
+			Default:     strconv.FormatBool(selected),
+			Optional:    true,
 		})
 	}
-	var dialogElements []model.DialogElement
-	dialogElements = append(dialogElements, model.DialogElement{
-		Name:        conf.SelectCalendarDialogOption,
-		DisplayName: "Select calendar for notifications",
-		Type:        "select",
-		Optional:    false,
-		Default:     settings.Calendar,
-		Options:     calendarOptions,
-	})
 
 	dialogElements = append(dialogElements, model.DialogElement{
 		Name:        conf.SelectTimezoneDialogOption,
-		DisplayName: "Select your timezone",
+		DisplayName: conf.T(language, conf.MsgTimezoneDialogElement),
 		Type:        "select",
 		Optional:    false,
 		Default:     settings.TimeZone,
@@ -114,17 +174,17 @@ func (s *Sender) getSettingsDialog(siteURL string, rootId string, calendars []ca
 	}
 	dialogElements = append(dialogElements, model.DialogElement{
 		Name:        conf.DailyNotifyTimeDialogOption,
-		DisplayName: "Select time for get daily schedule",
+		DisplayName: conf.T(language, conf.MsgDailyNotifyTimeDialogElement),
 		Type:        "select",
 		Optional:    false,
 		Default:     defaultDailyNotifyTime,
-		Options:     s.dailyNotifyTimeOptions,
+		Options:     getDailyNotifyTimeOptions(s.dailyNotifyTimeOptions, language),
 	})
 
 	if s.supportedUserCustomStatus {
 		dialogElements = append(dialogElements, model.DialogElement{
 			Name:        conf.ChangeStatusOnMeetDialogOption,
-			DisplayName: "Setup 'In meeting' status automatically",
+			DisplayName: conf.T(language, conf.MsgChangeStatusOnMeetDialogElement),
 			Type:        "bool",
 			Default:     strconv.FormatBool(settings.ChangeStatusOnMeet),
 			Optional:    true,
@@ -132,26 +192,160 @@ func (s *Sender) getSettingsDialog(siteURL string, rootId string, calendars []ca
 	}
 
 	dialogElements = append(dialogElements, model.DialogElement{
-		Name:        conf.TenMinuteNotifyDialogOption,
-		DisplayName: "Get notification in 10 minutes before event",
+		Name:        conf.ReminderOffsetsDialogOption,
+		DisplayName: conf.T(language, conf.MsgReminderOffsetsDialogElement),
+		Type:        "text",
+		Optional:    true,
+		Default:     util.FormatMinutesList(settings.ReminderOffsets),
+		Placeholder: "1h, 30m, 5m, 0m",
+		HelpText:    conf.T(language, conf.MsgReminderOffsetsDialogHelp),
+	})
+
+	eventAlarms := settings.EventAlarms
+	if eventAlarms == "" {
+		eventAlarms = dto.EventAlarmsOff
+	}
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventAlarmsDialogOption,
+		DisplayName: conf.T(language, conf.MsgEventAlarmsDialogElement),
+		Type:        "select",
+		Optional:    false,
+		Default:     eventAlarms,
+		Options: []*model.PostActionOptions{
+			{Text: conf.T(language, conf.MsgEventAlarmsOffOption), Value: dto.EventAlarmsOff},
+			{Text: conf.T(language, conf.MsgEventAlarmsInsteadOption), Value: dto.EventAlarmsInstead},
+			{Text: conf.T(language, conf.MsgEventAlarmsAdditionalOption), Value: dto.EventAlarmsAdditional},
+		},
+	})
+
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.ThreadedNotificationsDialogOption,
+		DisplayName: conf.T(language, conf.MsgThreadedDialogElement),
 		Type:        "bool",
-		Default:     strconv.FormatBool(settings.TenMinutesNotify),
+		Default:     strconv.FormatBool(settings.ThreadedNotifications),
 		Optional:    true,
 	})
 
 	dialogElements = append(dialogElements, model.DialogElement{
-		Name:        conf.OneMinuteNotifyDialogOption,
-		DisplayName: "Get notification in 1 minute before event",
+		Name:        conf.ShareEventTitlesDialogOption,
+		DisplayName: conf.T(language, conf.MsgShareEventTitlesDialogElement),
 		Type:        "bool",
-		Default:     strconv.FormatBool(settings.OneMinutesNotify),
+		Default:     strconv.FormatBool(settings.ShareEventTitles),
+		Optional:    true,
+	})
+
+	workingHours := settings.GetWorkingHours()
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.WorkingHoursDialogOption,
+		DisplayName: conf.T(language, conf.MsgWorkingHoursDialogElement),
+		Type:        "text",
+		Optional:    true,
+		Default:     util.FormatWorkingHours(workingHours.Weekdays, workingHours.StartMinute, workingHours.EndMinute),
+		Placeholder: "Mon-Fri 09:00-18:00",
+		HelpText:    conf.T(language, conf.MsgWorkingHoursDialogHelp),
+	})
+
+	dialog := model.Dialog{
+		CallbackId:  rootId,
+		Title:       conf.T(language, conf.MsgSettingsDialogTitle),
+		IconURL:     conf.GetIconUrl(siteURL, s.manifestId),
+		SubmitLabel: conf.T(language, conf.MsgSettingsDialogSubmit),
+		Elements:    dialogElements,
+	}
+	return dialog
+}
+
+func (s *Sender) OpenCreateEventDialog(language string, triggerId string, rootId string, settings *dto.Settings) error {
+	siteURL := *s.serverConfig.ServiceSettings.SiteURL
+	dialog := model.OpenDialogRequest{
+		TriggerId: triggerId,
+		URL:       conf.ResolveUrlByPlugin(strings.ToLower(s.manifestId), conf.CalendarCreateEvent),
+		Dialog:    s.getCreateEventDialog(siteURL, rootId, language, settings),
+	}
+
+	if appErr := s.pluginAPI.OpenInteractiveDialog(dialog); appErr != nil {
+		s.logger.LogWarn("Failed to open create event dialog", nil, appErr)
+		return appErr
+	}
+	return nil
+}
+
+func (s *Sender) getCreateEventDialog(siteURL string, rootId string, language string, settings *dto.Settings) model.Dialog {
+	var calendarOptions []*model.PostActionOptions
+	for _, c := range settings.Calendars {
+		calendarOptions = append(calendarOptions, &model.PostActionOptions{
+			Text:  c.GetDisplayName(),
+			Value: c.Path,
+		})
+	}
+	userNow := settings.GetUserNow()
+	nextQuarter := userNow.Truncate(15 * time.Minute).Add(15 * time.Minute)
+
+	var dialogElements []model.DialogElement
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventTitleDialogOption,
+		DisplayName: conf.T(language, conf.MsgTitleDialogElement),
+		Type:        "text",
+		Optional:    false,
+		MaxLength:   255,
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventCalendarDialogOption,
+		DisplayName: conf.T(language, conf.MsgEventCalendarDialogElement),
+		Type:        "select",
+		Optional:    false,
+		Default:     settings.Calendars[0].Path,
+		Options:     calendarOptions,
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventDateDialogOption,
+		DisplayName: conf.T(language, conf.MsgDateDialogElement),
+		Type:        "text",
+		Optional:    false,
+		Default:     nextQuarter.Format(conf.DialogDateFormat),
+		HelpText:    conf.T(language, conf.MsgDateDialogHelp),
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventStartDialogOption,
+		DisplayName: conf.T(language, conf.MsgStartDialogElement),
+		Type:        "select",
+		Optional:    false,
+		Default:     nextQuarter.Format(timeOptionFormat),
+		Options:     s.eventStartOptions,
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventDurationDialogOption,
+		DisplayName: conf.T(language, conf.MsgDurationDialogElement),
+		Type:        "select",
+		Optional:    false,
+		Default:     (30 * time.Minute).String(),
+		Options:     getEventDurationOptions(language),
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventDescriptionDialogOption,
+		DisplayName: conf.T(language, conf.MsgDescriptionDialogElement),
+		Type:        "textarea",
+		Optional:    true,
+	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventLocationDialogOption,
+		DisplayName: conf.T(language, conf.MsgLocationDialogElement),
+		Type:        "text",
 		Optional:    true,
 	})
+	dialogElements = append(dialogElements, model.DialogElement{
+		Name:        conf.EventAttendeesDialogOption,
+		DisplayName: conf.T(language, conf.MsgAttendeesDialogElement),
+		Type:        "text",
+		Optional:    true,
+		HelpText:    conf.T(language, conf.MsgAttendeesDialogHelp),
+	})
 
 	dialog := model.Dialog{
 		CallbackId:  rootId,
-		Title:       "Settings",
+		Title:       conf.T(language, conf.MsgCreateEventDialogTitle),
 		IconURL:     conf.GetIconUrl(siteURL, s.manifestId),
-		SubmitLabel: "Save",
+		SubmitLabel: conf.T(language, conf.MsgCreateEventDialogSubmit),
 		Elements:    dialogElements,
 	}
 	return dialog
@@ -200,65 +394,701 @@ func prepareDailyNotifyTimeOptions() []*model.PostActionOptions {
 	return options
 }
 
-func (s *Sender) SendEvent(userId string, title string, event dto.Event) {
+func prepareEventStartOptions() []*model.PostActionOptions {
+	var options []*model.PostActionOptions
+	for h := 0; h < 24; h++ {
+		for m := 0; m < 60; m += 15 {
+			value := time.Date(1, 1, 1, h, m, 0, 0, time.UTC).Format(timeOptionFormat)
+			options = append(options, &model.PostActionOptions{
+				Text:  value,
+				Value: value,
+			})
+		}
+	}
+	return options
+}
+
+// getDailyNotifyTimeOptions translates option to disable daily schedule, time options are shared
+func getDailyNotifyTimeOptions(options []*model.PostActionOptions, language string) []*model.PostActionOptions {
+	localizedOptions := []*model.PostActionOptions{{
+		Text:  conf.T(language, conf.MsgDailyNotifyTimeDisableOption),
+		Value: conf.DailyNotifyTimeDisableOption,
+	}}
+	return append(localizedOptions, options[1:]...)
+}
+
+func getEventDurationOptions(language string) []*model.PostActionOptions {
+	durations := []time.Duration{
+		15 * time.Minute,
+		30 * time.Minute,
+		45 * time.Minute,
+		time.Hour,
+		90 * time.Minute,
+		2 * time.Hour,
+		3 * time.Hour,
+		4 * time.Hour,
+		8 * time.Hour,
+	}
+	var options []*model.PostActionOptions
+	for _, d := range durations {
+		options = append(options, &model.PostActionOptions{
+			Text:  util.FormatLocalizedDuration(d, language),
+			Value: d.String(),
+		})
+	}
+	return options
+}
+
+func (s *Sender) SendEvent(userId string, language string, title string, event dto.Event) {
 	var attachments []*model.SlackAttachment
-	attachments = append(attachments, s.getFormattedEventAttachment(event))
-	err := s.sendEvents(userId, title, attachments)
+	attachments = append(attachments, s.getFormattedEventAttachment(event, nil, language))
+	err := s.sendEvents(userId, language, title, attachments)
 	if err != nil {
 		s.logger.LogError("Couldn't send one event to user from bot", &userId, err)
 	}
 }
 
-func (s *Sender) SendEvents(userId string, title string, events []dto.Event) {
+func (s *Sender) SendEvents(userId string, language string, title string, events []dto.Event) {
+	s.SendEventsReply(userId, language, "", title, events)
+}
+
+// SendEventsReply sends events in thread of rootId, post is top level if rootId is empty.
+// Id of created post is returned, it's empty if post wasn't sent
+func (s *Sender) SendEventsReply(userId string, language string, rootId string, title string, events []dto.Event) string {
+	return s.sendEventsWithTemplate(userId, language, rootId, title, events, "")
+}
+
+// SendDigest sends daily schedule formatted by digest template
+func (s *Sender) SendDigest(userId string, language string, title string, events []dto.Event) string {
+	return s.sendEventsWithTemplate(userId, language, "", title, events, digestTemplate)
+}
+
+// SendEventUpdates sends changed events formatted by update template in thread of rootId
+func (s *Sender) SendEventUpdates(userId string, language string, rootId string, title string, events []dto.Event) string {
+	return s.sendEventsWithTemplate(userId, language, rootId, title, events, updateTemplate)
+}
+
+func (s *Sender) sendEventsWithTemplate(
+	userId string,
+	language string,
+	rootId string,
+	title string,
+	events []dto.Event,
+	templateName string) string {
+
+	tmpl := s.templates.getEventTemplate(templateName)
+	attachments, occurrenceIds := s.getEventAttachments(events, tmpl, language)
+	title = s.getTemplateTitle(tmpl, title, events, language)
+	post, err := s.sendEventsReply(userId, language, rootId, title, attachments, occurrenceIds, templateName)
+	if err != nil {
+		s.logger.LogError("Couldn't send events to user from bot", &userId, err)
+		return ""
+	}
+	return post.Id
+}
+
+// SendAgenda sends events of [start, end] grouped by day, days without events are skipped.
+// Days are split into several posts to keep posts within size limits
+func (s *Sender) SendAgenda(userId string, language string, title string, start time.Time, end time.Time, events []dto.Event) {
 	var attachments []*model.SlackAttachment
+	postSize := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		dayEnd := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, day.Location())
+		var dayEvents []dto.Event
+		for _, event := range events {
+			if event.Overlaps(dayStart, dayEnd) {
+				dayEvents = append(dayEvents, event)
+			}
+		}
+		if len(dayEvents) == 0 {
+			continue
+		}
+		dayAttachments, _ := s.getEventAttachments(dayEvents, nil, language)
+		dayAttachments[0].Pretext = strings.TrimSpace(conf.GetEventsTitle(language, "", dayStart) + "\n" + dayAttachments[0].Pretext)
+		daySize := getAttachmentsSize(dayAttachments)
+		if len(attachments) > 0 && (len(attachments)+len(dayAttachments) > conf.AgendaPostMaxAttachments ||
+			postSize+daySize > conf.AgendaPostMaxSize) {
+			if err := s.sendEvents(userId, language, title, attachments); err != nil {
+				s.logger.LogError("Couldn't send agenda to user from bot", &userId, err)
+			}
+			title = ""
+			attachments = nil
+			postSize = 0
+		}
+		attachments = append(attachments, dayAttachments...)
+		postSize += daySize
+	}
+	if len(attachments) > 0 || title != "" {
+		if err := s.sendEvents(userId, language, title, attachments); err != nil {
+			s.logger.LogError("Couldn't send agenda to user from bot", &userId, err)
+		}
+	}
+}
+
+// getEventAttachments shows all day events first and then events with time, text of events is rendered by tmpl if it's set.
+// Occurrence ids of events are returned in order of attachments
+func (s *Sender) getEventAttachments(
+	events []dto.Event,
+	tmpl *template.Template,
+	language string) ([]*model.SlackAttachment, []string) {
+
+	var wholeDayAttachments, timedAttachments []*model.SlackAttachment
+	var wholeDayIds, timedIds []string
 	for _, event := range events {
-		attachments = append(attachments, s.getFormattedEventAttachment(event))
+		if event.IsWholeDay() {
+			wholeDayAttachments = append(wholeDayAttachments, s.getFormattedEventAttachment(event, tmpl, language))
+			wholeDayIds = append(wholeDayIds, event.GetOccurrenceId())
+		} else {
+			timedAttachments = append(timedAttachments, s.getFormattedEventAttachment(event, tmpl, language))
+			timedIds = append(timedIds, event.GetOccurrenceId())
+		}
+	}
+	if len(wholeDayAttachments) > 0 {
+		wholeDayAttachments[0].Pretext = conf.T(language, conf.MsgAllDayEventsSubtitle)
+		if len(timedAttachments) > 0 {
+			timedAttachments[0].Pretext = conf.T(language, conf.MsgTimedEventsSubtitle)
+		}
 	}
-	err := s.sendEvents(userId, title, attachments)
+	return append(wholeDayAttachments, timedAttachments...), append(wholeDayIds, timedIds...)
+}
+
+func getAttachmentsSize(attachments []*model.SlackAttachment) int {
+	size := 0
+	for _, attachment := range attachments {
+		size += len(attachment.Pretext) + len(attachment.Title) + len(attachment.Text) + len(attachment.Footer)
+	}
+	return size
+}
+
+// SendReminder sends upcoming event with prominent link to join conference in thread of rootId.
+// Id of created post is returned, it's empty if post wasn't sent
+func (s *Sender) SendReminder(userId string, language string, rootId string, title string, event dto.Event) string {
+	attachment := s.getReminderAttachment(event, language)
+	title = s.getTemplateTitle(s.templates.getEventTemplate(reminderTemplate), title, []dto.Event{event}, language)
+	post, err := s.sendEventsReply(userId, language, rootId, title, []*model.SlackAttachment{attachment},
+		[]string{event.GetOccurrenceId()}, reminderTemplate)
 	if err != nil {
-		s.logger.LogError("Couldn't send events to user from bot", &userId, err)
+		s.logger.LogError("Couldn't send reminder to user from bot", &userId, err)
+		return ""
+	}
+	return post.Id
+}
+
+func (s *Sender) getReminderAttachment(event dto.Event, language string) *model.SlackAttachment {
+	attachment := s.getFormattedEventAttachment(event, s.templates.getEventTemplate(reminderTemplate), language)
+	if event.ConferenceUrl != "" {
+		attachment.Pretext = "#### " + conf.ConferenceEventMark + " [" + conf.T(language, conf.MsgJoinConferenceLink) + "](" + event.ConferenceUrl + ")"
 	}
+	return attachment
 }
 
-func (s *Sender) sendEvents(userId string, title string, attachments []*model.SlackAttachment) *model.AppError {
+func (s *Sender) sendEvents(userId string, language string, title string, attachments []*model.SlackAttachment) *model.AppError {
+	_, err := s.sendEventsReply(userId, language, "", title, attachments, nil, "")
+	return err
+}
+
+// sendEventsReply posts attachments in thread of rootId, post becomes top level if root was deleted.
+// Occurrence ids of attachments and name of their template are kept in post to update events later
+func (s *Sender) sendEventsReply(
+	userId string,
+	language string,
+	rootId string,
+	title string,
+	attachments []*model.SlackAttachment,
+	occurrenceIds []string,
+	templateName string) (*model.Post, *model.AppError) {
+
 	channel, err := s.pluginAPI.GetDirectChannel(userId, s.botId)
 	if err != nil {
 		s.logger.LogError("Couldn't get bot's DM channel", &userId, err)
+		return nil, err
 	}
+	return s.sendChannelEventsReply(channel.Id, rootId, title, attachments, occurrenceIds, templateName, language)
+}
+
+func (s *Sender) sendChannelEventsReply(
+	channelId string,
+	rootId string,
+	title string,
+	attachments []*model.SlackAttachment,
+	occurrenceIds []string,
+	templateName string,
+	language string) (*model.Post, *model.AppError) {
+
 	var post *model.Post
 	if len(attachments) == 0 {
 		post = &model.Post{
 			UserId:    s.botId,
-			ChannelId: channel.Id,
+			ChannelId: channelId,
+			RootId:    rootId,
 			Type:      model.PostTypeDefault,
-			Message:   title + "\nNo events",
+			Message:   title + "\n" + conf.T(language, conf.MsgNoEventsMessage),
 		}
 	} else {
 		post = &model.Post{
 			UserId:    s.botId,
-			ChannelId: channel.Id,
+			ChannelId: channelId,
+			RootId:    rootId,
 			Type:      model.PostTypeSlackAttachment,
 			Message:   title,
 		}
 		post.AddProp("attachments", attachments)
+		if len(occurrenceIds) > 0 {
+			post.AddProp(conf.EventOccurrenceIdsPostProp, occurrenceIds)
+			post.AddProp(conf.EventTemplatePostProp, templateName)
+		}
+	}
+	return s.sendReply(post)
+}
+
+// sendReply sends post in its thread, post becomes top level if root was deleted
+func (s *Sender) sendReply(post *model.Post) (*model.Post, *model.AppError) {
+	createdPost, appErr := s.sendPost(post)
+	if appErr != nil && post.RootId != "" {
+		post.RootId = ""
+		return s.sendPost(post)
+	}
+	return createdPost, appErr
+}
+
+// SendTasks shows tasks with button to mark them done in thread of rootId, overdue tasks are marked
+func (s *Sender) SendTasks(userId string, language string, rootId string, title string, tasks []dto.Task, now time.Time) {
+	if len(tasks) == 0 {
+		s.SendBotDMPost(userId, title+"\n"+conf.T(language, conf.MsgNoTasksMessage))
+		return
+	}
+	var attachments []*model.SlackAttachment
+	for _, task := range tasks {
+		attachments = append(attachments, s.getFormattedTaskAttachment(task, now, language))
+	}
+	_, err := s.sendEventsReply(userId, language, rootId, title, attachments, nil, "")
+	if err != nil {
+		s.logger.LogError("Couldn't send tasks to user from bot", &userId, err)
 	}
-	return s.sendPost(post)
 }
 
-func (s *Sender) getFormattedEventAttachment(event dto.Event) *model.SlackAttachment {
-	title := event.GetStartTimeFormatted() + " - " + event.GetEndTimeFormatted()
-	title += " [" + event.Name + "](" + event.Url + ")"
+func (s *Sender) getFormattedTaskAttachment(task dto.Task, now time.Time, language string) *model.SlackAttachment {
+	title := task.GetDueFormatted(language)
+	if task.Url == "" {
+		title += " " + task.Name
+	} else {
+		title += " [" + task.Name + "](" + task.Url + ")"
+	}
+	if task.IsOverdue(now) {
+		title += " " + conf.T(language, conf.MsgOverdueTaskMark)
+	}
 	return &model.SlackAttachment{
-		Color: "blue",
-		Title: title,
-		Text:  event.GetDescriptionFormatted(),
+		Color:  getCalendarColor(task.CalendarPath),
+		Title:  title,
+		Text:   task.Description,
+		Footer: task.CalendarName,
+		Actions: []*model.PostAction{{
+			Type:  model.PostActionTypeButton,
+			Name:  conf.T(language, conf.MsgCompleteTaskAction),
+			Style: "good",
+			Integration: &model.PostActionIntegration{
+				URL: conf.ResolveUrlByPlugin(strings.ToLower(s.manifestId), conf.CalendarCompleteTask),
+				Context: map[string]interface{}{
+					conf.EventObjectPathActionContext: task.ObjectPath,
+					conf.TaskIdActionContext:          task.Id,
+				},
+			},
+		}},
+	}
+}
+
+// SendBusyIntervals shows busy time of colleague in location of user
+func (s *Sender) SendBusyIntervals(
+	userId string,
+	language string,
+	title string,
+	intervals []dto.BusyInterval,
+	location *time.Location) {
+
+	lines := []string{title}
+	for _, interval := range intervals {
+		name := conf.T(language, conf.MsgBusyIntervalName)
+		if len(interval.Names) > 0 {
+			name = strings.Join(interval.Names, ", ")
+		}
+		lines = append(lines, "* "+interval.StartTime.In(location).Format(timeOptionFormat)+
+			" - "+interval.EndTime.In(location).Format(timeOptionFormat)+" "+name)
+	}
+	if len(intervals) == 0 {
+		lines = append(lines, conf.T(language, conf.MsgFreeDayMessage))
+	}
+	s.SendBotDMPost(userId, strings.Join(lines, "\n"))
+}
+
+// SendFreeSlots shows common free slots with button to create meeting for all participants
+func (s *Sender) SendFreeSlots(
+	userId string,
+	language string,
+	slots []dto.TimeSlot,
+	participantIds []string,
+	location *time.Location) {
+
+	if len(slots) == 0 {
+		s.SendBotDMPost(userId, conf.T(language, conf.MsgFreeSlotsTitle)+"\n"+conf.T(language, conf.MsgNoFreeSlotsMessage))
+		return
+	}
+	var attachments []*model.SlackAttachment
+	for _, slot := range slots {
+		start := slot.StartTime.In(location)
+		attachments = append(attachments, &model.SlackAttachment{
+			Title: util.FormatShortWeekdayDate(start, language) + " " + start.Format(timeOptionFormat) + " - " +
+				slot.EndTime.In(location).Format(timeOptionFormat),
+			Actions: []*model.PostAction{{
+				Type:  model.PostActionTypeButton,
+				Name:  conf.T(language, conf.MsgCreateSlotAction),
+				Style: "primary",
+				Integration: &model.PostActionIntegration{
+					URL: conf.ResolveUrlByPlugin(strings.ToLower(s.manifestId), conf.CalendarCreateSlot),
+					Context: map[string]interface{}{
+						conf.SlotStartActionContext:        slot.StartTime.Format(time.RFC3339),
+						conf.SlotEndActionContext:          slot.EndTime.Format(time.RFC3339),
+						conf.SlotParticipantsActionContext: strings.Join(participantIds, ","),
+					},
+				},
+			}},
+		})
+	}
+	err := s.sendEvents(userId, language, conf.T(language, conf.MsgFreeSlotsTitle), attachments)
+	if err != nil {
+		s.logger.LogError("Couldn't send free slots to user from bot", &userId, err)
+	}
+}
+
+// UpdateEventPosts shows actual event in posts which show it, previous time is struck through.
+// Cancelled event is struck through entirely
+func (s *Sender) UpdateEventPosts(
+	userId string,
+	language string,
+	postIds []string,
+	event dto.Event,
+	previous *dto.Event,
+	cancelled bool) {
+
+	if len(postIds) == 0 {
+		return
+	}
+	cancelledMark := conf.T(language, conf.MsgCancelledEventMark)
+	for _, postId := range postIds {
+		post, appErr := s.pluginAPI.GetPost(postId)
+		if appErr != nil {
+			s.logger.LogWarn("Couldn't get post "+postId, &userId, appErr)
+			continue
+		}
+		attachments := post.Attachments()
+		occurrenceIds, templateName, ok := getPostEvents(post, attachments)
+		if !ok {
+			continue
+		}
+		changed := false
+		for i, attachment := range attachments {
+			if occurrenceIds[i] != event.GetOccurrenceId() {
+				continue
+			}
+			if cancelled {
+				if !strings.HasSuffix(attachment.Title, cancelledMark) {
+					attachment.Title = "~~" + attachment.Title + "~~ " + cancelledMark
+				}
+				attachment.Actions = nil
+			} else {
+				updatedAttachment := s.getFormattedEventAttachment(event, s.templates.getEventTemplate(templateName), language)
+				updatedAttachment.Pretext = attachment.Pretext
+				if previous != nil {
+					previousTime, eventTime := getEventTimeChange(*previous, event, language)
+					eventTitle := updatedAttachment.Title
+					if strings.HasPrefix(eventTitle, getEventTimeFormatted(event, language)) {
+						eventTitle = eventTime + strings.TrimPrefix(eventTitle, getEventTimeFormatted(event, language))
+					}
+					if previousTime != eventTime {
+						updatedAttachment.Title = "~~" + previousTime + "~~ " + eventTitle
+					}
+				}
+				attachments[i] = updatedAttachment
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		model.ParseSlackAttachment(post, attachments)
+		if _, appErr := s.pluginAPI.UpdatePost(post); appErr != nil {
+			s.logger.LogWarn("Couldn't update post "+postId, &userId, appErr)
+		}
+	}
+}
+
+// getPostEvents returns occurrence ids of post attachments and name of template they were rendered by.
+// Post without ids in props can't be edited, so false is returned
+func getPostEvents(post *model.Post, attachments []*model.SlackAttachment) ([]string, string, bool) {
+	occurrenceIds := make([]string, len(attachments))
+	switch ids := post.GetProp(conf.EventOccurrenceIdsPostProp).(type) {
+	case []string:
+		copy(occurrenceIds, ids)
+	case []interface{}:
+		for i := 0; i < len(ids) && i < len(occurrenceIds); i++ {
+			occurrenceIds[i], _ = ids[i].(string)
+		}
+	default:
+		return nil, "", false
+	}
+	templateName, _ := post.GetProp(conf.EventTemplatePostProp).(string)
+	return occurrenceIds, templateName, true
+}
+
+// SendEventChanges sends short notice about changed or cancelled events in thread of rootId
+func (s *Sender) SendEventChanges(
+	userId string,
+	language string,
+	rootId string,
+	title string,
+	events []dto.Event,
+	previousById map[string]dto.Event,
+	cancelled bool) string {
+
+	channel, appErr := s.pluginAPI.GetDirectChannel(userId, s.botId)
+	if appErr != nil {
+		s.logger.LogError("Couldn't get bot's DM channel", &userId, appErr)
+		return ""
+	}
+	post := &model.Post{
+		UserId:    s.botId,
+		ChannelId: channel.Id,
+		RootId:    rootId,
+		Message:   s.getEventChangesMessage(title, events, previousById, cancelled, language),
+	}
+	createdPost, appErr := s.sendReply(post)
+	if appErr != nil {
+		s.logger.LogError("Couldn't send event changes to user from bot", &userId, appErr)
+		return ""
+	}
+	return createdPost.Id
+}
+
+// getEventChangesMessage lists changed events, previous time is struck through, cancelled events are struck through entirely.
+// Title is rendered by update template
+func (s *Sender) getEventChangesMessage(
+	title string,
+	events []dto.Event,
+	previousById map[string]dto.Event,
+	cancelled bool,
+	language string) string {
+
+	lines := []string{s.getTemplateTitle(s.templates.getEventTemplate(updateTemplate), title, events, language)}
+	for _, event := range events {
+		eventTime := getEventTimeFormatted(event, language)
+		if previous, ok := previousById[event.GetOccurrenceId()]; ok && !cancelled {
+			var previousTime string
+			previousTime, eventTime = getEventTimeChange(previous, event, language)
+			if previousTime != eventTime {
+				eventTime = "~~" + previousTime + "~~ " + eventTime
+			}
+		}
+		line := eventTime + " " + event.Name
+		if cancelled {
+			line = "~~" + line + "~~"
+		}
+		lines = append(lines, "* "+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JoinChannel adds bot to channel, so it could post there
+func (s *Sender) JoinChannel(channelId string) *model.AppError {
+	_, appErr := s.pluginAPI.AddChannelMember(channelId, s.botId)
+	return appErr
+}
+
+// SendChannelPost posts message of bot to channel, bot should be member of it
+func (s *Sender) SendChannelPost(channelId string, message string) {
+	post := &model.Post{
+		UserId:    s.botId,
+		ChannelId: channelId,
+		Message:   message,
+	}
+	if _, err := s.sendPost(post); err != nil {
+		s.logger.LogError("Couldn't send message to channel "+channelId, nil, err)
+	}
+}
+
+// SendChannelDigest sends daily schedule of channel formatted by digest template
+func (s *Sender) SendChannelDigest(channelId string, language string, title string, events []dto.Event) {
+	tmpl := s.templates.getEventTemplate(digestTemplate)
+	attachments, occurrenceIds := s.getEventAttachments(events, tmpl, language)
+	title = s.getTemplateTitle(tmpl, title, events, language)
+	if _, err := s.sendChannelEventsReply(channelId, "", title, attachments, occurrenceIds, digestTemplate, language); err != nil {
+		s.logger.LogError("Couldn't send digest to channel "+channelId, nil, err)
+	}
+}
+
+// SendChannelEventUpdates sends added events of channel formatted by update template
+func (s *Sender) SendChannelEventUpdates(channelId string, language string, title string, events []dto.Event) {
+	tmpl := s.templates.getEventTemplate(updateTemplate)
+	attachments, occurrenceIds := s.getEventAttachments(events, tmpl, language)
+	title = s.getTemplateTitle(tmpl, title, events, language)
+	if _, err := s.sendChannelEventsReply(channelId, "", title, attachments, occurrenceIds, updateTemplate, language); err != nil {
+		s.logger.LogError("Couldn't send event updates to channel "+channelId, nil, err)
+	}
+}
+
+// SendChannelReminder sends started event of channel with prominent link to join conference
+func (s *Sender) SendChannelReminder(channelId string, language string, title string, event dto.Event) {
+	attachments := []*model.SlackAttachment{s.getReminderAttachment(event, language)}
+	occurrenceIds := []string{event.GetOccurrenceId()}
+	title = s.getTemplateTitle(s.templates.getEventTemplate(reminderTemplate), title, []dto.Event{event}, language)
+	if _, err := s.sendChannelEventsReply(channelId, "", title, attachments, occurrenceIds, reminderTemplate, language); err != nil {
+		s.logger.LogError("Couldn't send reminder to channel "+channelId, nil, err)
+	}
+}
+
+// SendChannelEventChanges sends short notice about changed or cancelled events of channel
+func (s *Sender) SendChannelEventChanges(
+	channelId string,
+	language string,
+	title string,
+	events []dto.Event,
+	previousById map[string]dto.Event,
+	cancelled bool) {
+
+	s.SendChannelPost(channelId, s.getEventChangesMessage(title, events, previousById, cancelled, language))
+}
+
+// getEventTimeChange formats times of event before and after change, date is added if event was moved to another day
+func getEventTimeChange(previous dto.Event, event dto.Event, language string) (string, string) {
+	previousTime := getEventTimeFormatted(previous, language)
+	eventTime := getEventTimeFormatted(event, language)
+	if !event.IsWholeDay() && !util.IsSameDay(previous.StartTime, event.StartTime, event.StartTime.Location()) {
+		previousTime = util.FormatShortDate(previous.StartTime.In(event.StartTime.Location()), language) + " " + previousTime
+		eventTime = util.FormatShortDate(event.StartTime, language) + " " + eventTime
+	}
+	return previousTime, eventTime
+}
+
+func getEventTimeFormatted(event dto.Event, language string) string {
+	if event.IsWholeDay() {
+		return event.GetDatesFormatted(language)
+	}
+	return event.GetStartTimeFormatted() + " - " + event.GetEndTimeFormatted()
+}
+
+// getFormattedEventAttachment shows event with its time as title, text below is rendered by tmpl if it's set
+func (s *Sender) getFormattedEventAttachment(event dto.Event, tmpl *template.Template, language string) *model.SlackAttachment {
+	title := getEventTimeFormatted(event, language)
+	if event.Url == "" {
+		title += " " + event.Name
+	} else {
+		title += " [" + event.Name + "](" + event.Url + ")"
+	}
+	if status := getEventStatusMark(event, language); status != "" {
+		title += " " + status
+	}
+	var text []string
+	if event.Location != "" {
+		text = append(text, conf.LocationEventMark+" "+event.Location)
+	}
+	if event.ConferenceUrl != "" && !strings.Contains(event.Location, event.ConferenceUrl) {
+		text = append(text, conf.ConferenceEventMark+" ["+conf.T(language, conf.MsgConferenceLinkName)+"]("+event.ConferenceUrl+")")
+	}
+	if event.Description != "" {
+		text = append(text, event.GetDescriptionFormatted())
+	}
+	attachment := &model.SlackAttachment{
+		Fallback: getEventTimeFormatted(event, language) + " " + event.Name,
+		Color:    getCalendarColor(event.CalendarPath),
+		Title:    title,
+		Text:     strings.Join(text, "\n"),
+		Footer:   event.CalendarName,
+	}
+	if tmpl != nil {
+		data := getEventTemplateData(event, language)
+		parts := map[string]*string{
+			"":                  &attachment.Text,
+			eventTitleTemplate:  &attachment.Title,
+			eventFooterTemplate: &attachment.Footer,
+		}
+		for part, value := range parts {
+			partTmpl := lookupTemplatePart(tmpl, part)
+			if partTmpl == nil {
+				continue
+			}
+			rendered, err := renderTemplate(partTmpl, data)
+			if err != nil {
+				s.logger.LogWarn("Can't render "+tmpl.Name()+" template for event "+event.Name, nil, err)
+				continue
+			}
+			*value = rendered
+		}
 	}
+	if event.IsNeedsAction() && event.ObjectPath != "" {
+		attachment.Actions = []*model.PostAction{
+			s.getEventResponseAction(event, conf.T(language, conf.MsgAcceptEventAction), dto.PartStatAccepted, "good"),
+			s.getEventResponseAction(event, conf.T(language, conf.MsgTentativeEventAction), dto.PartStatTentative, "default"),
+			s.getEventResponseAction(event, conf.T(language, conf.MsgDeclineEventAction), dto.PartStatDeclined, "danger"),
+		}
+	}
+	return attachment
+}
+
+// getTemplateTitle renders title of message by "title" part of tmpl, built-in title is kept if it's not defined
+func (s *Sender) getTemplateTitle(tmpl *template.Template, title string, events []dto.Event, language string) string {
+	titleTmpl := lookupTemplatePart(tmpl, titleTemplate)
+	if titleTmpl == nil {
+		return title
+	}
+	rendered, err := renderTemplate(titleTmpl, MessageTemplateData{Title: title, Events: getEventsTemplateData(events, language)})
+	if err != nil {
+		s.logger.LogWarn("Can't render title of "+tmpl.Name()+" template", nil, err)
+		return title
+	}
+	return rendered
+}
+
+// getEventStatusMark marks events which user hasn't accepted yet
+func getEventStatusMark(event dto.Event, language string) string {
+	if event.IsTentative() {
+		return conf.T(language, conf.MsgTentativeEventMark)
+	} else if event.IsNeedsAction() {
+		return conf.T(language, conf.MsgNeedsActionEventMark)
+	}
+	return ""
 }
 
-func (s *Sender) sendPost(post *model.Post) *model.AppError {
-	if _, err := s.pluginAPI.CreatePost(post); err != nil {
+func (s *Sender) getEventResponseAction(event dto.Event, name string, partStat string, style string) *model.PostAction {
+	return &model.PostAction{
+		Type:  model.PostActionTypeButton,
+		Name:  name,
+		Style: style,
+		Integration: &model.PostActionIntegration{
+			URL: conf.ResolveUrlByPlugin(strings.ToLower(s.manifestId), conf.CalendarRespondEvent),
+			Context: map[string]interface{}{
+				conf.EventObjectPathActionContext:   event.ObjectPath,
+				conf.EventRecurrenceIdActionContext: event.RecurrenceId,
+				conf.EventOccurrenceIdActionContext: event.GetOccurrenceId(),
+				conf.EventPartStatActionContext:     partStat,
+			},
+		},
+	}
+}
+
+// getCalendarColor picks stable color for calendar to distinguish events from different calendars
+func getCalendarColor(calendarPath string) string {
+	if calendarPath == "" {
+		return calendarColors[0]
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(calendarPath))
+	return calendarColors[hash.Sum32()%uint32(len(calendarColors))]
+}
+
+func (s *Sender) sendPost(post *model.Post) (*model.Post, *model.AppError) {
+	createdPost, err := s.pluginAPI.CreatePost(post)
+	if err != nil {
 		s.logger.LogError("Couldn't send post", nil, err)
-		return err
+		return nil, err
 	}
-	return nil
+	return createdPost, nil
 }
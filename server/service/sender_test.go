@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+func TestGetPostEvents(t *testing.T) {
+	attachments := []*model.SlackAttachment{{Fallback: "10:00 First"}, {Fallback: "11:00 Second"}}
+	tests := []struct {
+		name         string
+		props        model.StringInterface
+		wantIds      []string
+		wantTemplate string
+		wantEditable bool
+	}{
+		{
+			name: "ids sent by plugin",
+			props: model.StringInterface{
+				conf.EventOccurrenceIdsPostProp: []string{"a", "b"},
+				conf.EventTemplatePostProp:      digestTemplate,
+			},
+			wantIds:      []string{"a", "b"},
+			wantTemplate: digestTemplate,
+			wantEditable: true,
+		},
+		{
+			name: "ids loaded from database",
+			props: model.StringInterface{
+				conf.EventOccurrenceIdsPostProp: []interface{}{"a", "b"},
+				conf.EventTemplatePostProp:      reminderTemplate,
+			},
+			wantIds:      []string{"a", "b"},
+			wantTemplate: reminderTemplate,
+			wantEditable: true,
+		},
+		{
+			name:  "post without ids",
+			props: model.StringInterface{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{Props: tt.props}
+			ids, templateName, editable := getPostEvents(post, attachments)
+			if editable != tt.wantEditable {
+				t.Fatalf("got editable %v, want %v", editable, tt.wantEditable)
+			}
+			if !editable {
+				return
+			}
+			if len(ids) != len(tt.wantIds) || ids[0] != tt.wantIds[0] || ids[1] != tt.wantIds[1] {
+				t.Errorf("got ids %v, want %v", ids, tt.wantIds)
+			}
+			if templateName != tt.wantTemplate {
+				t.Errorf("got template %q, want %q", templateName, tt.wantTemplate)
+			}
+		})
+	}
+}
+
+func TestGetDailyNotifyTimeOptions(t *testing.T) {
+	options := prepareDailyNotifyTimeOptions()
+	tests := []struct {
+		name     string
+		language string
+		wantText string
+	}{
+		{"english", util.LanguageEn, "Never"},
+		{"russian", util.LanguageRu, "Никогда"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localized := getDailyNotifyTimeOptions(options, tt.language)
+			if localized[0].Text != tt.wantText {
+				t.Errorf("got text %q, want %q", localized[0].Text, tt.wantText)
+			}
+			if localized[0].Value != conf.DailyNotifyTimeDisableOption {
+				t.Errorf("got value %q, want %q", localized[0].Value, conf.DailyNotifyTimeDisableOption)
+			}
+			if len(localized) != len(options) || localized[1].Value != options[1].Value {
+				t.Errorf("time options aren't kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// MessageTemplates keeps templates set by admin, built-in format is used for empty ones
+type MessageTemplates struct {
+	digest   *template.Template
+	reminder *template.Template
+	update   *template.Template
+	welcome  *template.Template
+}
+
+const (
+	digestTemplate   = "digest"
+	reminderTemplate = "reminder"
+	updateTemplate   = "update"
+	welcomeTemplate  = "welcome"
+)
+
+// Parts of message layout which event templates could define by {{define "name"}}, built-in format is used for others
+const (
+	titleTemplate       = "title"
+	eventTitleTemplate  = "eventTitle"
+	eventFooterTemplate = "eventFooter"
+)
+
+// MessageTemplateData is passed to "title" template, it renders message above events
+type MessageTemplateData struct {
+	Title  string
+	Events []EventTemplateData
+}
+
+// EventTemplateData is passed to templates of events, body of template renders text below title of event
+type EventTemplateData struct {
+	Name          string
+	Status        string
+	Time          string
+	Start         string
+	End           string
+	Date          string
+	AllDay        bool
+	Location      string
+	ConferenceUrl string
+	Description   string
+	Url           string
+	Organizer     string
+	Attendees     []string
+	Calendar      string
+}
+
+// WelcomeTemplateData is passed to template of welcome message
+type WelcomeTemplateData struct {
+	Command string
+}
+
+// NewMessageTemplates parses templates and checks they are rendered for sample event
+func NewMessageTemplates(digest string, reminder string, update string, welcome string) (*MessageTemplates, error) {
+	templates := &MessageTemplates{}
+	var err error
+	if templates.digest, err = parseEventTemplate(digestTemplate, digest); err != nil {
+		return nil, err
+	}
+	if templates.reminder, err = parseEventTemplate(reminderTemplate, reminder); err != nil {
+		return nil, err
+	}
+	if templates.update, err = parseEventTemplate(updateTemplate, update); err != nil {
+		return nil, err
+	}
+	if templates.welcome, err = parseTemplate(welcomeTemplate, welcome, getWelcomeTemplateData()); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// parseTemplate returns nil for empty text, template is executed for sample data to find unknown fields
+func parseTemplate(name string, text string, sample interface{}) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse "+name+" template")
+	}
+	if err = tmpl.Execute(ioutil.Discard, sample); err != nil {
+		return nil, errors.Wrap(err, "Can't render "+name+" template")
+	}
+	return tmpl, nil
+}
+
+// parseEventTemplate parses template of events, its parts are executed for sample data to find unknown fields
+func parseEventTemplate(name string, text string) (*template.Template, error) {
+	sample := getSampleEventTemplateData()
+	tmpl, err := parseTemplate(name, text, sample)
+	if tmpl == nil || err != nil {
+		return tmpl, err
+	}
+	parts := map[string]interface{}{
+		titleTemplate:       MessageTemplateData{Title: "Today's events", Events: []EventTemplateData{sample}},
+		eventTitleTemplate:  sample,
+		eventFooterTemplate: sample,
+	}
+	for part, data := range parts {
+		if partTmpl := tmpl.Lookup(part); partTmpl != nil {
+			if err = partTmpl.Execute(ioutil.Discard, data); err != nil {
+				return nil, errors.Wrap(err, "Can't render "+part+" of "+name+" template")
+			}
+		}
+	}
+	return tmpl, nil
+}
+
+// lookupTemplatePart returns part of tmpl defined as name, body of tmpl is returned for empty name.
+// Nil is returned if part isn't defined or body has only definitions
+func lookupTemplatePart(tmpl *template.Template, name string) *template.Template {
+	if tmpl == nil {
+		return nil
+	}
+	if name != "" {
+		return tmpl.Lookup(name)
+	}
+	if tmpl.Tree == nil || parse.IsEmptyTree(tmpl.Tree.Root) {
+		return nil
+	}
+	return tmpl
+}
+
+// getEventTemplate returns template of events by its name, nil is returned for unknown name
+func (t *MessageTemplates) getEventTemplate(name string) *template.Template {
+	if t == nil {
+		return nil
+	}
+	switch name {
+	case digestTemplate:
+		return t.digest
+	case reminderTemplate:
+		return t.reminder
+	case updateTemplate:
+		return t.update
+	}
+	return nil
+}
+
+func (t *MessageTemplates) getWelcome() *template.Template {
+	if t == nil {
+		return nil
+	}
+	return t.welcome
+}
+
+func renderTemplate(tmpl *template.Template, data interface{}) (string, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buffer.String()), nil
+}
+
+func getEventTemplateData(event dto.Event, language string) EventTemplateData {
+	data := EventTemplateData{
+		Name:          event.Name,
+		Status:        getEventStatusMark(event, language),
+		Time:          getEventTimeFormatted(event, language),
+		Start:         event.GetStartTimeFormatted(),
+		End:           event.GetEndTimeFormatted(),
+		Date:          util.FormatShortDate(event.StartTime, language),
+		AllDay:        event.IsWholeDay(),
+		Location:      event.Location,
+		ConferenceUrl: event.ConferenceUrl,
+		Description:   event.GetDescriptionFormatted(),
+		Url:           event.Url,
+		Calendar:      event.CalendarName,
+	}
+	if event.Organizer != nil {
+		data.Organizer = event.Organizer.GetDisplayName()
+	}
+	for _, attendee := range event.Attendees {
+		data.Attendees = append(data.Attendees, attendee.GetDisplayName())
+	}
+	return data
+}
+
+func getEventsTemplateData(events []dto.Event, language string) []EventTemplateData {
+	var data []EventTemplateData
+	for _, event := range events {
+		data = append(data, getEventTemplateData(event, language))
+	}
+	return data
+}
+
+func getSampleEventTemplateData() EventTemplateData {
+	start := time.Date(2024, time.March, 25, 10, 0, 0, 0, time.UTC)
+	event := dto.NewEvent("sample", "", "Planning", "Agenda", "https://calendar.yandex.ru", "UTC",
+		start, start.Add(time.Hour), false, start)
+	event.Location = "Room 1"
+	event.ConferenceUrl = "https://telemost.yandex.ru"
+	event.Organizer = &dto.Attendee{Email: "organizer@yandex.ru"}
+	event.Attendees = []dto.Attendee{{Email: "attendee@yandex.ru"}}
+	return getEventTemplateData(*event, util.LanguageEn)
+}
+
+func getWelcomeTemplateData() WelcomeTemplateData {
+	return WelcomeTemplateData{
+		Command: "/calendar help",
+	}
+}
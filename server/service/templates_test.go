@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
+)
+
+func TestEventTemplateParts(t *testing.T) {
+	digest := `{{define "title"}}{{.Title}}: {{len .Events}}{{end}}
+{{define "eventTitle"}}{{.Start}} {{.Name}}{{end}}
+{{define "eventFooter"}}{{end}}`
+	templates, err := NewMessageTemplates(digest, ":round_pushpin: {{.Location}}", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := &Sender{templates: templates}
+	start := time.Date(2026, time.October, 19, 10, 0, 0, 0, time.UTC)
+	event := dto.NewEvent("id", "", "Planning", "Agenda", "", "UTC", start, start.Add(time.Hour), false, start)
+	event.Location = "Room 1"
+	event.CalendarName = "Work"
+
+	title := sender.getTemplateTitle(templates.getEventTemplate(digestTemplate), "Today", []dto.Event{*event}, util.LanguageEn)
+	if title != "Today: 1" {
+		t.Errorf("got digest title %q", title)
+	}
+	attachment := sender.getFormattedEventAttachment(*event, templates.getEventTemplate(digestTemplate), util.LanguageEn)
+	if attachment.Title != "10:00 Planning" || attachment.Footer != "" {
+		t.Errorf("got digest attachment title %q, footer %q", attachment.Title, attachment.Footer)
+	}
+	if attachment.Text == "" {
+		t.Error("built-in text should be kept if template has only definitions")
+	}
+
+	title = sender.getTemplateTitle(templates.getEventTemplate(reminderTemplate), "Soon", []dto.Event{*event}, util.LanguageEn)
+	if title != "Soon" {
+		t.Errorf("got reminder title %q", title)
+	}
+	attachment = sender.getFormattedEventAttachment(*event, templates.getEventTemplate(reminderTemplate), util.LanguageEn)
+	if attachment.Text != ":round_pushpin: Room 1" || attachment.Footer != "Work" {
+		t.Errorf("got reminder attachment text %q, footer %q", attachment.Text, attachment.Footer)
+	}
+}
+
+func TestNewMessageTemplatesValidatesParts(t *testing.T) {
+	if _, err := NewMessageTemplates(`{{define "title"}}{{.Name}}{{end}}`, "", "", ""); err == nil {
+		t.Error("unknown field of title should be an error")
+	}
+}
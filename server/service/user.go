@@ -2,11 +2,13 @@ package service
 
 import (
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/conf"
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/convertor"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/repository"
 	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/util"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin"
+	"sync"
 	"time"
 )
 
@@ -17,6 +19,8 @@ type User struct {
 	credentialsRepo           *repository.CredentialsRepo
 	sender                    *Sender
 	calendar                  *Calendar
+	// threadLocks keep mutex by user id, reminders and updates change threads of user concurrently
+	threadLocks sync.Map
 }
 
 func NewUserService(
@@ -36,83 +40,153 @@ func NewUserService(
 	}
 }
 
-func (u *User) Connect(userId string, triggerId string, rootId string, credentials dto.Credentials) {
+func (u *User) Connect(userId string, language string, triggerId string, rootId string, credentials dto.Credentials) {
 	u.credentialsRepo.SaveCredentials(userId, credentials)
 	calendarHomeSet, err := u.calendar.GetCalendarHomeSet(userId)
 	if err != nil {
-		u.sender.SendBotDMPost(userId, err.Error())
+		u.logger.LogWarn("Can't get calendar home set", &userId, err)
+		u.sender.SendBotDMPost(userId, conf.T(language, conf.MsgCalendarHomeSetError))
 		return
 	}
 	repository.SaveCalendarHomeSet(u.pluginAPI, userId, calendarHomeSet)
-	u.sender.SendWelcomePost(userId)
-	u.Settings(userId, triggerId, rootId)
+	u.sender.SendWelcomePost(userId, language)
+	u.Settings(userId, language, triggerId, rootId)
 }
 
-func (u *User) Settings(userId string, triggerId string, rootId string) {
+func (u *User) Settings(userId string, language string, triggerId string, rootId string) {
 	calendars, _ := u.calendar.FindCalendars(userId)
 	settings := repository.GetSettings(u.pluginAPI, userId)
 	if settings == nil {
 		settings = dto.DefaultSettings()
 	}
-	err := u.sender.OpenSettingsDialog(triggerId, rootId, calendars, settings)
+	err := u.sender.OpenSettingsDialog(language, triggerId, rootId, calendars, settings)
 	if err != nil {
 		u.logger.LogError("Couldn't open settings dialog", &userId, err)
 	}
 }
 
+func (u *User) OpenCreateEventDialog(userId string, language string, triggerId string, rootId string) {
+	settings := repository.GetSettings(u.pluginAPI, userId)
+	if settings == nil || len(settings.Calendars) == 0 {
+		u.sender.SendBotDMPost(userId, conf.T(language, conf.MsgNotConnectedMessage))
+		return
+	}
+	err := u.sender.OpenCreateEventDialog(language, triggerId, rootId, settings)
+	if err != nil {
+		u.logger.LogError("Couldn't open create event dialog", &userId, err)
+	}
+}
+
 func (u *User) UserEventsHandler(userId string) {
 	userSettings := repository.GetSettings(u.pluginAPI, userId)
+	userNow := userSettings.GetUserNow()
 	events := repository.GetEvents(u.pluginAPI, userId)
-	u.remindUser(userId, userSettings.GetUserNow(), userSettings, events)
-	u.updateUserEventStatus(userId, userSettings.GetUserNow(), userSettings, events)
+	language := u.sender.GetLanguageOnce(userId)
+	u.remindUser(userId, language, userNow, userSettings, events)
+	u.updateUserEventStatus(userId, language, userNow, userSettings, events)
 }
 
-func (u *User) remindUser(userId string, userNow time.Time, userSettings *dto.Settings, events []dto.Event) {
+func (u *User) remindUser(
+	userId string,
+	language func() string,
+	userNow time.Time,
+	userSettings *dto.Settings,
+	events []dto.Event) {
+
+	defer u.lockThreads(userId)()
+	threads := repository.GetThreads(u.pluginAPI, userId)
+	threadsChanged := false
 	if userSettings.DailyNotifyTime != nil &&
-		userNow.Hour() == userSettings.DailyNotifyTime.Hour() &&
-		userNow.Minute() == userSettings.DailyNotifyTime.Minute() {
-		u.sender.SendEvents(userId, conf.GetTodayEventsTitle(userNow), events)
+		util.IsDailyTime(userNow, userSettings.DailyNotifyTime.Hour(), userSettings.DailyNotifyTime.Minute()) {
+		todayEvents := getDayEvents(events, userNow)
+		postId := u.sender.SendDigest(userId, language(), conf.GetTodayEventsTitle(language(), userNow), todayEvents)
+		threads.AddMentions(postId, todayEvents)
+		threadsChanged = postId != ""
+		if userSettings.ThreadedNotifications && postId != "" {
+			threads.SetDigest(userNow, postId, todayEvents)
+		}
+		u.sendTodayTasks(userId, language(), getDigestRootId(userSettings, threads, userNow), userNow)
+	}
+	for _, task := range repository.GetTasks(u.pluginAPI, userId) {
+		if task.IsDueAt(userNow) {
+			title := conf.T(language(), conf.MsgTaskDueTitle)
+			u.sender.SendTasks(userId, language(), getDigestRootId(userSettings, threads, userNow), title, []dto.Task{task}, userNow)
+		}
 	}
-	tenMinutesLater := userNow.Add(10 * time.Minute)
 	for _, event := range events {
-		if event.StartBefore(userNow) || event.StartAfter(tenMinutesLater) {
+		if event.IsDeclined() {
+			continue
+		}
+		title := ""
+		// Offsets aren't negative, so events which already started don't match
+		if userSettings.UseReminderOffsets() && event.IsMeeting() {
+			if offset, ok := getReminderOffset(&event, userNow, userSettings.ReminderOffsets); ok {
+				title = conf.GetReminderTitle(language(), offset)
+			}
+		}
+		// Alarm at the same minute as reminder offset isn't sent twice
+		if alarm := event.GetAlarmAt(userNow); userSettings.UseEventAlarms() && title == "" && alarm != nil {
+			untilAnchor := alarm.GetAnchor(&event).Sub(userNow)
+			title = conf.GetAlarmTitle(language(), untilAnchor, alarm.IsRelatedToEnd())
+		}
+		if title == "" {
 			continue
 		}
-		//TODO check attendees
-		if userSettings.TenMinutesNotify && event.StartEquals(tenMinutesLater) {
-			u.sender.SendEvent(userId, conf.TenMinutesEventTitle, event)
+		rootId := getEventRootId(userSettings, threads, event)
+		postId := u.sender.SendReminder(userId, language(), rootId, title, event)
+		threads.AddMentions(postId, []dto.Event{event})
+		threadsChanged = threadsChanged || postId != ""
+		if userSettings.ThreadedNotifications && rootId == "" && postId != "" {
+			threads.EventPostIds[event.GetOccurrenceId()] = postId
+			threadsChanged = true
 		}
-		oneMinuteLater := userNow.Add(1 * time.Minute)
-		if userSettings.OneMinutesNotify && event.StartEquals(oneMinuteLater) {
-			u.sender.SendEvent(userId, conf.OneMinuteEventTitle, event)
+	}
+	if threadsChanged {
+		repository.SaveThreads(u.pluginAPI, userId, *threads)
+	}
+}
+
+// sendTodayTasks adds overdue and due today tasks to daily schedule
+func (u *User) sendTodayTasks(userId string, language string, rootId string, userNow time.Time) {
+	var tasks []dto.Task
+	for _, task := range repository.GetTasks(u.pluginAPI, userId) {
+		if task.IsOverdue(userNow) || task.IsDueOn(userNow) {
+			tasks = append(tasks, task)
 		}
 	}
+	if len(tasks) > 0 {
+		u.sender.SendTasks(userId, language, rootId, conf.T(language, conf.MsgTodayTasksTitle), tasks, userNow)
+	}
 }
 
-func (u *User) updateUserEventStatus(userId string, userNow time.Time, userSettings *dto.Settings, events []dto.Event) {
+func (u *User) updateUserEventStatus(
+	userId string,
+	language func() string,
+	userNow time.Time,
+	userSettings *dto.Settings,
+	events []dto.Event) {
+
 	if !u.supportedUserCustomStatus || !userSettings.ChangeStatusOnMeet || len(events) == 0 {
 		return
 	}
 	userState := repository.GetState(u.pluginAPI, userId)
-	if userState != nil && userState.CurrentEvent != nil &&
-		userState.CurrentEvent.StartBeforeOrEquals(userNow) &&
-		userState.CurrentEvent.EndAfterOrEquals(userNow) {
+	if userState != nil && userState.CurrentEvent != nil && userState.CurrentEvent.IsActiveAt(userNow) {
 		return
 	}
 	var currentEvent *dto.Event
-	for _, event := range events {
-		if event.StartBeforeOrEquals(userNow) && event.EndAfterOrEquals(userNow) {
-			currentEvent = &event
+	for i := range events {
+		if events[i].IsMeeting() && !events[i].IsDeclined() && events[i].IsActiveAt(userNow) {
+			currentEvent = &events[i]
 			break
 		}
 	}
 	if currentEvent != nil {
-		end := currentEvent.EndTime
+		// Status expires at the end of event even if it's on the next day or after DST transition
 		err := u.pluginAPI.UpdateUserCustomStatus(userId, &model.CustomStatus{
 			Emoji:     "calendar",
-			Text:      "In meeting",
+			Text:      conf.T(language(), conf.MsgInMeetingStatus),
 			Duration:  "date_and_time",
-			ExpiresAt: time.Date(userNow.Year(), userNow.Month(), userNow.Day(), end.Hour(), end.Minute(), 0, 0, userNow.Location()),
+			ExpiresAt: getStatusExpiresAt(currentEvent, userNow.Location()),
 		})
 		if err != nil {
 			u.logger.LogWarn("Error in update custom status", &userId, err)
@@ -124,14 +198,117 @@ func (u *User) updateUserEventStatus(userId string, userNow time.Time, userSetti
 	repository.SaveState(u.pluginAPI, userId, *userState)
 }
 
+// getReminderOffset finds offset in minutes which comes for event at minute of userNow, event could start on the next day
+func getReminderOffset(event *dto.Event, userNow time.Time, offsets []int) (int, bool) {
+	for _, offset := range offsets {
+		if event.StartEquals(userNow.Add(time.Duration(offset) * time.Minute)) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+func getStatusExpiresAt(event *dto.Event, location *time.Location) time.Time {
+	return event.EndTime.In(location)
+}
+
+// LoadEventUpdates edits posts which show changed events and sends short notice about changes
 func (u *User) LoadEventUpdates(userId string) {
-	addedEvents, updatedEvents := u.calendar.LoadCalendarUpdates(userId)
+	previousEventsById := convertor.SliceEventToMapByOccurrenceId(repository.GetEvents(u.pluginAPI, userId))
+	addedEvents, updatedEvents, removedEvents := u.calendar.LoadCalendarUpdates(userId)
+	language := u.sender.GetLanguageOnce(userId)
+	userSettings := repository.GetSettings(u.pluginAPI, userId)
+	defer u.lockThreads(userId)()
+	threads := repository.GetThreads(u.pluginAPI, userId)
+	threadsChanged := false
 	if addedEvents != nil {
-		u.sender.SendEvents(userId, conf.AddedEventsTitle, addedEvents)
+		threadsChanged = u.sendEventUpdates(addedEvents, userSettings, threads, func(rootId string, events []dto.Event) string {
+			postId := u.sender.SendEventUpdates(userId, language(), rootId, conf.T(language(), conf.MsgAddedEventsTitle), events)
+			threads.AddMentions(postId, events)
+			threadsChanged = threadsChanged || postId != ""
+			return postId
+		}) || threadsChanged
 	}
 	if updatedEvents != nil {
-		u.sender.SendEvents(userId, conf.UpdatedEventsTitle, updatedEvents)
+		for _, event := range updatedEvents {
+			var previous *dto.Event
+			if previousEvent, ok := previousEventsById[event.GetOccurrenceId()]; ok {
+				previous = &previousEvent
+			}
+			u.sender.UpdateEventPosts(userId, language(), threads.MentionPostIds[event.GetOccurrenceId()], event, previous, false)
+		}
+		threadsChanged = u.sendEventUpdates(updatedEvents, userSettings, threads, func(rootId string, events []dto.Event) string {
+			return u.sender.SendEventChanges(userId, language(), rootId, conf.T(language(), conf.MsgUpdatedEventsTitle), events, previousEventsById, false)
+		}) || threadsChanged
+	}
+	if removedEvents != nil {
+		for _, event := range removedEvents {
+			u.sender.UpdateEventPosts(userId, language(), threads.MentionPostIds[event.GetOccurrenceId()], event, nil, true)
+		}
+		threadsChanged = u.sendEventUpdates(removedEvents, userSettings, threads, func(rootId string, events []dto.Event) string {
+			return u.sender.SendEventChanges(userId, language(), rootId, conf.T(language(), conf.MsgRemovedEventsTitle), events, previousEventsById, true)
+		}) || threadsChanged
+	}
+	threadsChanged = threads.Prune(repository.GetEvents(u.pluginAPI, userId)) || threadsChanged
+	if threadsChanged {
+		repository.SaveThreads(u.pluginAPI, userId, *threads)
+	}
+}
+
+// lockThreads locks threads of user until returned unlock is called
+func (u *User) lockThreads(userId string) func() {
+	lock, _ := u.threadLocks.LoadOrStore(userId, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	return lock.(*sync.Mutex).Unlock
+}
+
+// sendEventUpdates replies events in threads of their previous notifications.
+// Events without them are sent together and the post becomes their thread, true is returned if threads were changed
+func (u *User) sendEventUpdates(
+	events []dto.Event,
+	userSettings *dto.Settings,
+	threads *dto.Threads,
+	send func(rootId string, events []dto.Event) string) bool {
+
+	threaded := userSettings != nil && userSettings.ThreadedNotifications
+	var rootIds []string
+	eventsByRootId := make(map[string][]dto.Event)
+	for _, event := range events {
+		rootId := ""
+		if threaded {
+			rootId = threads.EventPostIds[event.GetOccurrenceId()]
+		}
+		if _, ok := eventsByRootId[rootId]; !ok {
+			rootIds = append(rootIds, rootId)
+		}
+		eventsByRootId[rootId] = append(eventsByRootId[rootId], event)
+	}
+	changed := false
+	for _, rootId := range rootIds {
+		postId := send(rootId, eventsByRootId[rootId])
+		if !threaded || rootId != "" || postId == "" {
+			continue
+		}
+		for _, event := range eventsByRootId[rootId] {
+			threads.EventPostIds[event.GetOccurrenceId()] = postId
+		}
+		changed = true
+	}
+	return changed
+}
+
+func getEventRootId(userSettings *dto.Settings, threads *dto.Threads, event dto.Event) string {
+	if !userSettings.ThreadedNotifications {
+		return ""
+	}
+	return threads.EventPostIds[event.GetOccurrenceId()]
+}
+
+func getDigestRootId(userSettings *dto.Settings, threads *dto.Threads, userNow time.Time) string {
+	if !userSettings.ThreadedNotifications {
+		return ""
 	}
+	return threads.GetDigestPostId(userNow)
 }
 
 func (u *User) IsUserExist(userId string) bool {
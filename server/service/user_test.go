@@ -0,0 +1,115 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lugamuga/mattermost-yandex-calendar-plugin/server/dto"
+)
+
+func TestGetReminderOffset(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := []int{60, 10, 0}
+	tests := []struct {
+		name       string
+		eventStart time.Time
+		userNow    time.Time
+		wantOffset int
+		wantOk     bool
+	}{
+		{
+			name:       "the same day",
+			eventStart: time.Date(2026, 3, 10, 12, 0, 0, 0, location),
+			userNow:    time.Date(2026, 3, 10, 11, 50, 0, 0, location),
+			wantOffset: 10,
+			wantOk:     true,
+		},
+		{
+			name:       "ten minutes over midnight",
+			eventStart: time.Date(2026, 3, 11, 0, 5, 0, 0, location),
+			userNow:    time.Date(2026, 3, 10, 23, 55, 0, 0, location),
+			wantOffset: 10,
+			wantOk:     true,
+		},
+		{
+			name:       "hour over midnight",
+			eventStart: time.Date(2026, 3, 11, 0, 30, 0, 0, location),
+			userNow:    time.Date(2026, 3, 10, 23, 30, 0, 0, location),
+			wantOffset: 60,
+			wantOk:     true,
+		},
+		{
+			name:       "at midnight",
+			eventStart: time.Date(2026, 3, 11, 0, 0, 0, 0, location),
+			userNow:    time.Date(2026, 3, 11, 0, 0, 0, 0, location),
+			wantOffset: 0,
+			wantOk:     true,
+		},
+		{
+			name:       "the same time of the previous day",
+			eventStart: time.Date(2026, 3, 11, 0, 5, 0, 0, location),
+			userNow:    time.Date(2026, 3, 10, 0, 5, 0, 0, location),
+			wantOk:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := dto.Event{StartTime: tt.eventStart, EndTime: tt.eventStart.Add(time.Hour)}
+			offset, ok := getReminderOffset(&event, tt.userNow, offsets)
+			if ok != tt.wantOk || offset != tt.wantOffset {
+				t.Errorf("getReminderOffset() = %v, %v, want %v, %v", offset, ok, tt.wantOffset, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGetStatusExpiresAt(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		event dto.Event
+		want  time.Time
+	}{
+		{
+			name: "the same day",
+			event: dto.Event{
+				StartTime: time.Date(2026, 3, 10, 10, 0, 0, 0, location),
+				EndTime:   time.Date(2026, 3, 10, 11, 0, 0, 0, location),
+			},
+			want: time.Date(2026, 3, 10, 11, 0, 0, 0, location),
+		},
+		{
+			name: "the next day",
+			event: dto.Event{
+				StartTime: time.Date(2026, 3, 10, 23, 30, 0, 0, location),
+				EndTime:   time.Date(2026, 3, 11, 0, 30, 0, 0, location),
+			},
+			want: time.Date(2026, 3, 11, 0, 30, 0, 0, location),
+		},
+		{
+			name: "event in UTC",
+			event: dto.Event{
+				StartTime: time.Date(2026, 3, 10, 20, 30, 0, 0, time.UTC),
+				EndTime:   time.Date(2026, 3, 10, 21, 30, 0, 0, time.UTC),
+			},
+			want: time.Date(2026, 3, 11, 0, 30, 0, 0, location),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getStatusExpiresAt(&tt.event, location)
+			if !got.Equal(tt.want) || got.Location() != location {
+				t.Errorf("getStatusExpiresAt() = %v, want %v", got, tt.want)
+			}
+			if got.Day() != tt.want.Day() {
+				t.Errorf("getStatusExpiresAt() is on day %v, want %v", got.Day(), tt.want.Day())
+			}
+		})
+	}
+}
@@ -47,3 +47,32 @@ func (w *Workspace) GetUserIds() map[string]bool {
 	}
 	return *userIds
 }
+
+func (w *Workspace) AddChannel(channelId string) {
+	w.Lock()
+	defer w.Unlock()
+	channelIds := w.GetChannelIds()
+	if !channelIds[channelId] {
+		channelIds[channelId] = true
+		w.repo.SaveChannelIds(channelIds)
+	}
+}
+
+func (w *Workspace) DeleteChannel(channelId string) {
+	w.Lock()
+	defer w.Unlock()
+	channelIds := w.GetChannelIds()
+	if channelIds[channelId] {
+		delete(channelIds, channelId)
+		w.repo.SaveChannelIds(channelIds)
+	}
+	w.repo.DeleteChannel(channelId)
+}
+
+func (w *Workspace) GetChannelIds() map[string]bool {
+	channelIds := w.repo.GetChannelIds()
+	if channelIds == nil {
+		return make(map[string]bool)
+	}
+	return *channelIds
+}
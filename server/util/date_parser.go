@@ -0,0 +1,205 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ids of messages in bundles of conf
+const (
+	WrongDateMessage  = "wrongDateMessage"
+	WrongDatesMessage = "wrongDatesMessage"
+	EmptyDateMessage  = "emptyDateMessage"
+	WrongRangeMessage = "wrongRangeMessage"
+)
+
+var dateLayouts = []string{"02.01.2006", "2.1.2006", "2006-01-02", "02/01/2006", "2/1/2006"}
+
+var shortDateLayouts = []string{"02.01", "2.1"}
+
+var relativeDays = map[string]int{
+	"today":       0,
+	"tomorrow":    1,
+	"yesterday":   -1,
+	"сегодня":     0,
+	"завтра":      1,
+	"послезавтра": 2,
+	"вчера":       -1,
+	"позавчера":   -2,
+}
+
+var weekdays = map[string]time.Weekday{
+	"monday":      time.Monday,
+	"mon":         time.Monday,
+	"tuesday":     time.Tuesday,
+	"tue":         time.Tuesday,
+	"wednesday":   time.Wednesday,
+	"wed":         time.Wednesday,
+	"thursday":    time.Thursday,
+	"thu":         time.Thursday,
+	"friday":      time.Friday,
+	"fri":         time.Friday,
+	"saturday":    time.Saturday,
+	"sat":         time.Saturday,
+	"sunday":      time.Sunday,
+	"sun":         time.Sunday,
+	"понедельник": time.Monday,
+	"пн":          time.Monday,
+	"вторник":     time.Tuesday,
+	"вт":          time.Tuesday,
+	"среда":       time.Wednesday,
+	"среду":       time.Wednesday,
+	"ср":          time.Wednesday,
+	"четверг":     time.Thursday,
+	"чт":          time.Thursday,
+	"пятница":     time.Friday,
+	"пятницу":     time.Friday,
+	"пт":          time.Friday,
+	"суббота":     time.Saturday,
+	"субботу":     time.Saturday,
+	"сб":          time.Saturday,
+	"воскресенье": time.Sunday,
+	"вс":          time.Sunday,
+}
+
+var nextWords = map[string]bool{
+	"next":      true,
+	"следующий": true,
+	"следующая": true,
+	"следующую": true,
+	"следующее": true,
+	"след":      true,
+}
+
+// unitDays keeps length of relative offset units in days, Russian word forms are included
+var unitDays = map[string]int{
+	"d":      1,
+	"day":    1,
+	"days":   1,
+	"w":      7,
+	"week":   7,
+	"weeks":  7,
+	"д":      1,
+	"день":   1,
+	"дня":    1,
+	"дней":   1,
+	"н":      7,
+	"неделю": 7,
+	"недели": 7,
+	"недель": 7,
+}
+
+// relativeOffsetRegexp matches "+2", "-1", "+1w", "in 3 days", "3 days ago", "через 3 дня", "2 дня назад"
+var relativeOffsetRegexp = regexp.MustCompile(`^(?:(in|через)\s+)?([+-]?\d+)\s*([\p{L}]*)(?:\s+(ago|назад))?$`)
+
+// ParseDate parses date expression relative to now and returns start of the day in location of now.
+// Supported are dd.MM.yyyy, dd.MM, yyyy-MM-dd, today/tomorrow/yesterday, weekday names with optional "next"
+// and relative offsets like +2 or "in 3 days", all of them also in Russian
+func ParseDate(expression string, now time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.Join(strings.Fields(expression), " "))
+	value = strings.TrimPrefix(value, "on ")
+	value = strings.TrimPrefix(value, "в ")
+	value = strings.TrimPrefix(value, "во ")
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if days, ok := relativeDays[value]; ok {
+		return today.AddDate(0, 0, days), nil
+	}
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return date, nil
+		}
+	}
+	for _, layout := range shortDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location()), nil
+		}
+	}
+	if date, ok := parseWeekday(value, today); ok {
+		return date, nil
+	}
+	if date, ok := parseRelativeOffset(value, today); ok {
+		return date, nil
+	}
+	return time.Time{}, &InputError{Message: WrongDateMessage, Value: expression}
+}
+
+// ParseDateRange parses one or two date expressions from words, the end is the same day if only one date is given.
+// Dates in reversed order are reported by WrongRangeMessage
+func ParseDateRange(words []string, now time.Time) (time.Time, time.Time, error) {
+	if len(words) == 0 {
+		return time.Time{}, time.Time{}, &InputError{Message: EmptyDateMessage}
+	}
+	if date, err := ParseDate(strings.Join(words, " "), now); err == nil {
+		return date, date, nil
+	}
+	reversed := false
+	for i := 1; i < len(words); i++ {
+		start, err := ParseDate(strings.Join(words[:i], " "), now)
+		if err != nil {
+			continue
+		}
+		end, err := ParseDate(strings.Join(words[i:], " "), now)
+		if err != nil {
+			continue
+		}
+		if end.Before(start) {
+			reversed = true
+			continue
+		}
+		return start, end, nil
+	}
+	if reversed {
+		return time.Time{}, time.Time{}, &InputError{Message: WrongRangeMessage, Value: strings.Join(words, " ")}
+	}
+	return time.Time{}, time.Time{}, &InputError{Message: WrongDatesMessage, Value: strings.Join(words, " ")}
+}
+
+// parseWeekday returns the nearest weekday starting from today, "next" weekday is taken from the next week
+func parseWeekday(value string, today time.Time) (time.Time, bool) {
+	next := false
+	if parts := strings.SplitN(value, " ", 2); len(parts) == 2 && nextWords[parts[0]] {
+		next = true
+		value = parts[1]
+	}
+	weekday, ok := weekdays[value]
+	if !ok {
+		return time.Time{}, false
+	}
+	if next {
+		daysFromMonday := (int(today.Weekday()) + 6) % 7
+		nextMonday := today.AddDate(0, 0, 7-daysFromMonday)
+		return nextMonday.AddDate(0, 0, (int(weekday)+6)%7), true
+	}
+	return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7), true
+}
+
+func parseRelativeOffset(value string, today time.Time) (time.Time, bool) {
+	match := relativeOffsetRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, false
+	}
+	prefix, number, unit, suffix := match[1], match[2], match[3], match[4]
+	// Plain number without sign, unit or words is ambiguous
+	if prefix == "" && suffix == "" && unit == "" && !strings.HasPrefix(number, "+") && !strings.HasPrefix(number, "-") {
+		return time.Time{}, false
+	}
+	count, err := strconv.Atoi(number)
+	if err != nil {
+		return time.Time{}, false
+	}
+	days := 1
+	if unit != "" {
+		unitLength, ok := unitDays[unit]
+		if !ok {
+			return time.Time{}, false
+		}
+		days = unitLength
+	}
+	if suffix != "" {
+		count = -count
+	}
+	return today.AddDate(0, 0, count*days), true
+}
@@ -0,0 +1,139 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday
+	now := time.Date(2026, 3, 11, 15, 30, 0, 0, location)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, location)
+	}
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+		wantError  string
+	}{
+		{name: "full date", expression: "15.03.2026", want: day(3, 15)},
+		{name: "full date without leading zeros", expression: "5.3.2026", want: day(3, 5)},
+		{name: "iso date", expression: "2026-03-15", want: day(3, 15)},
+		{name: "date with slashes", expression: "15/03/2026", want: day(3, 15)},
+		{name: "date of current year", expression: "15.03", want: day(3, 15)},
+		{name: "date of current year without leading zeros", expression: "1.4", want: day(4, 1)},
+		{name: "today", expression: "today", want: day(3, 11)},
+		{name: "tomorrow in upper case with spaces", expression: "  Tomorrow ", want: day(3, 12)},
+		{name: "yesterday", expression: "yesterday", want: day(3, 10)},
+		{name: "russian today", expression: "сегодня", want: day(3, 11)},
+		{name: "russian tomorrow", expression: "завтра", want: day(3, 12)},
+		{name: "russian day after tomorrow", expression: "послезавтра", want: day(3, 13)},
+		{name: "russian yesterday", expression: "вчера", want: day(3, 10)},
+		{name: "russian day before yesterday", expression: "позавчера", want: day(3, 9)},
+		{name: "weekday later this week", expression: "friday", want: day(3, 13)},
+		{name: "short weekday", expression: "fri", want: day(3, 13)},
+		{name: "weekday of today", expression: "wednesday", want: day(3, 11)},
+		{name: "weekday of next week", expression: "monday", want: day(3, 16)},
+		{name: "weekday with on", expression: "on friday", want: day(3, 13)},
+		{name: "next weekday", expression: "next friday", want: day(3, 20)},
+		{name: "next weekday of today", expression: "next wednesday", want: day(3, 18)},
+		{name: "russian weekday", expression: "пятница", want: day(3, 13)},
+		{name: "russian weekday in accusative", expression: "в пятницу", want: day(3, 13)},
+		{name: "russian weekday with во", expression: "во вторник", want: day(3, 17)},
+		{name: "russian short weekday", expression: "пн", want: day(3, 16)},
+		{name: "russian next weekday", expression: "следующий понедельник", want: day(3, 16)},
+		{name: "russian next weekday in accusative", expression: "в следующую среду", want: day(3, 18)},
+		{name: "plus days", expression: "+2", want: day(3, 13)},
+		{name: "minus days", expression: "-1", want: day(3, 10)},
+		{name: "plus weeks", expression: "+1w", want: day(3, 18)},
+		{name: "in days", expression: "in 3 days", want: day(3, 14)},
+		{name: "in week", expression: "in 1 week", want: day(3, 18)},
+		{name: "days ago", expression: "3 days ago", want: day(3, 8)},
+		{name: "russian in days", expression: "через 3 дня", want: day(3, 14)},
+		{name: "russian in weeks", expression: "через 2 недели", want: day(3, 25)},
+		{name: "russian days ago", expression: "2 дня назад", want: day(3, 9)},
+		{name: "wrong day", expression: "32.03.2026", wantError: WrongDateMessage},
+		{name: "wrong month", expression: "15.13.2026", wantError: WrongDateMessage},
+		{name: "day out of month", expression: "31.02", wantError: WrongDateMessage},
+		{name: "unknown word", expression: "someday", wantError: WrongDateMessage},
+		{name: "unknown unit", expression: "in 3 parsecs", wantError: WrongDateMessage},
+		{name: "plain number", expression: "3", wantError: WrongDateMessage},
+		{name: "next without weekday", expression: "next week", wantError: WrongDateMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, err := ParseDate(tt.expression, now)
+			if tt.wantError != "" {
+				assertInputError(t, err, tt.wantError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q) returned error %v", tt.expression, err)
+			}
+			if !date.Equal(tt.want) || date.Location() != location {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.expression, date, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Wednesday
+	now := time.Date(2026, 3, 11, 15, 30, 0, 0, location)
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, location)
+	}
+	tests := []struct {
+		name      string
+		words     []string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantError string
+	}{
+		{name: "one date", words: []string{"15.03"}, wantStart: day(3, 15), wantEnd: day(3, 15)},
+		{name: "one date of several words", words: []string{"next", "friday"}, wantStart: day(3, 20), wantEnd: day(3, 20)},
+		{name: "two dates", words: []string{"10.03", "15.03"}, wantStart: day(3, 10), wantEnd: day(3, 15)},
+		{name: "same dates", words: []string{"today", "сегодня"}, wantStart: day(3, 11), wantEnd: day(3, 11)},
+		{name: "date and words", words: []string{"tomorrow", "next", "friday"}, wantStart: day(3, 12), wantEnd: day(3, 20)},
+		{name: "russian words", words: []string{"сегодня", "через", "3", "дня"}, wantStart: day(3, 11), wantEnd: day(3, 14)},
+		{name: "empty", words: nil, wantError: EmptyDateMessage},
+		{name: "reversed range", words: []string{"15.03", "10.03"}, wantError: WrongRangeMessage},
+		{name: "unknown words", words: []string{"foo", "bar"}, wantError: WrongDatesMessage},
+		{name: "one unknown date", words: []string{"10.03", "someday"}, wantError: WrongDatesMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := ParseDateRange(tt.words, now)
+			if tt.wantError != "" {
+				assertInputError(t, err, tt.wantError)
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDateRange(%q) returned error %v", tt.words, err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("ParseDateRange(%q) = %v - %v, want %v - %v", tt.words, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func assertInputError(t *testing.T, err error, wantMessage string) {
+	t.Helper()
+	inputErr, ok := err.(*InputError)
+	if !ok {
+		t.Fatalf("got error %v, want input error %q", err, wantMessage)
+	}
+	if inputErr.Message != wantMessage {
+		t.Errorf("got error message %q, want %q", inputErr.Message, wantMessage)
+	}
+}
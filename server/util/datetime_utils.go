@@ -1,21 +1,194 @@
 package util
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func HoursMinutes(dt time.Time) int {
-	minutes := strconv.Itoa(dt.Minute())
-	if dt.Minute() == 0 {
-		minutes = "00"
-	} else if dt.Minute() < 10 {
-		minutes = "0" + minutes
+// IsDailyTime checks daily hour:minute comes at minute of now in its location.
+// Time skipped by DST transition comes at the first minute after it, repeated time comes only once
+func IsDailyTime(now time.Time, hour int, minute int) bool {
+	dailyMinute := hour*60 + minute
+	if getDayMinute(now) == dailyMinute {
+		hourAgo := now.Add(-time.Hour)
+		return !IsSameDay(hourAgo, now, now.Location()) || getDayMinute(hourAgo) != dailyMinute
 	}
-	hour := strconv.Itoa(dt.Hour())
-	if dt.Hour() == 0 {
-		hour = ""
+	minuteAgo := now.Add(-time.Minute)
+	return IsSameDay(minuteAgo, now, now.Location()) &&
+		getDayMinute(minuteAgo) < dailyMinute && getDayMinute(now) > dailyMinute
+}
+
+func getDayMinute(dt time.Time) int {
+	return dt.Hour()*60 + dt.Minute()
+}
+
+const recurrenceIdFormat = "20060102T150405Z"
+
+// FormatRecurrenceId formats start of occurrence in UTC to identify it inside recurring event
+func FormatRecurrenceId(dt time.Time) string {
+	return dt.UTC().Format(recurrenceIdFormat)
+}
+
+func ParseRecurrenceId(recurrenceId string) (time.Time, error) {
+	return time.Parse(recurrenceIdFormat, recurrenceId)
+}
+
+// IsSameDay checks both times have the same date in location
+func IsSameDay(first time.Time, second time.Time, location *time.Location) bool {
+	firstYear, firstMonth, firstDay := first.In(location).Date()
+	secondYear, secondMonth, secondDay := second.In(location).Date()
+	return firstYear == secondYear && firstMonth == secondMonth && firstDay == secondDay
+}
+
+const WrongDurationMessage = "wrongDurationMessage"
+
+// FormatDuration shows duration in hours and minutes, e.g. "1h 30m"
+func FormatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return strconv.Itoa(minutes) + "m"
+	}
+	if minutes == 0 {
+		return strconv.Itoa(hours) + "h"
+	}
+	return strconv.Itoa(hours) + "h " + strconv.Itoa(minutes) + "m"
+}
+
+// ParseMinutesList parses comma separated durations like "1h, 30m, 5" into minutes, number without unit is minutes
+func ParseMinutesList(value string) ([]int, error) {
+	minutesList := []int{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if minutes, err := strconv.Atoi(part); err == nil && minutes >= 0 {
+			minutesList = append(minutesList, minutes)
+			continue
+		}
+		d, err := time.ParseDuration(strings.Replace(part, " ", "", -1))
+		if err != nil || d < 0 {
+			return nil, &InputError{Message: WrongDurationMessage, Value: part}
+		}
+		minutesList = append(minutesList, int(d.Minutes()))
+	}
+	return minutesList, nil
+}
+
+// FormatMinutesList is reverse for ParseMinutesList
+func FormatMinutesList(minutesList []int) string {
+	var parts []string
+	for _, minutes := range minutesList {
+		parts = append(parts, FormatDuration(time.Duration(minutes)*time.Minute))
+	}
+	return strings.Join(parts, ", ")
+}
+
+const WrongWorkingHoursMessage = "wrongWorkingHoursMessage"
+
+// ParseWorkingHours parses weekdays and time of day like "Mon-Fri 09:00-18:00" or "Mon,Wed 10:00-16:00"
+// into weekdays and minutes of day. Weekdays are Monday to Friday if they are omitted
+func ParseWorkingHours(value string) ([]time.Weekday, int, int, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, 0, 0, &InputError{Message: WrongWorkingHoursMessage, Value: value}
+	}
+	timeRange := strings.SplitN(fields[len(fields)-1], "-", 2)
+	if len(timeRange) != 2 {
+		return nil, 0, 0, &InputError{Message: WrongWorkingHoursMessage, Value: value}
+	}
+	startMinute, startErr := parseDayMinute(timeRange[0])
+	endMinute, endErr := parseDayMinute(timeRange[1])
+	if startErr != nil || endErr != nil || startMinute >= endMinute {
+		return nil, 0, 0, &InputError{Message: WrongWorkingHoursMessage, Value: fields[len(fields)-1]}
+	}
+	workingDays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	if len(fields) > 1 {
+		var err error
+		if workingDays, err = parseWeekdays(strings.Join(fields[:len(fields)-1], "")); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	return workingDays, startMinute, endMinute, nil
+}
+
+// parseWeekdays parses comma separated weekdays and ranges of them like "Mon-Wed,Fri"
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var result []time.Weekday
+	added := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(value, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, ok := weekdays[strings.ToLower(strings.TrimSpace(bounds[0]))]
+		if !ok {
+			return nil, &InputError{Message: WrongWorkingHoursMessage, Value: part}
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[strings.ToLower(strings.TrimSpace(bounds[1]))]; !ok {
+				return nil, &InputError{Message: WrongWorkingHoursMessage, Value: part}
+			}
+		}
+		// range could go over the end of week, e.g. Fri-Mon
+		for weekday := first; ; weekday = (weekday + 1) % 7 {
+			if !added[weekday] {
+				added[weekday] = true
+				result = append(result, weekday)
+			}
+			if weekday == last {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseDayMinute parses time of day like 9:00 or 18:30, 24:00 is the end of day
+func parseDayMinute(value string) (int, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return 0, strconv.ErrSyntax
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	dayMinute := hour*60 + minute
+	if hour < 0 || minute < 0 || minute > 59 || dayMinute > 24*60 {
+		return 0, strconv.ErrRange
+	}
+	return dayMinute, nil
+}
+
+// FormatWorkingHours is reverse for ParseWorkingHours, successive weekdays are joined into ranges
+func FormatWorkingHours(workingDays []time.Weekday, startMinute int, endMinute int) string {
+	included := make(map[time.Weekday]bool)
+	for _, weekday := range workingDays {
+		included[weekday] = true
+	}
+	var parts []string
+	// week starts from Monday
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		if !included[weekday] || (i > 1 && included[time.Weekday((i-1)%7)]) {
+			continue
+		}
+		last := i
+		for last < 7 && included[time.Weekday((last+1)%7)] {
+			last++
+		}
+		part := weekday.String()[:3]
+		if last > i {
+			part += "-" + time.Weekday(last % 7).String()[:3]
+		}
+		parts = append(parts, part)
 	}
-	hourMinute, _ := strconv.Atoi(hour + minutes)
-	return hourMinute
+	return fmt.Sprintf("%s %02d:%02d-%02d:%02d", strings.Join(parts, ","),
+		startMinute/60, startMinute%60, endMinute/60, endMinute%60)
 }
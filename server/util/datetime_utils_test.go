@@ -0,0 +1,65 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsDailyTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		day    time.Time
+		hour   int
+		minute int
+		want   time.Time
+	}{
+		{
+			name:   "usual day",
+			day:    time.Date(2026, 3, 10, 0, 0, 0, 0, location),
+			hour:   7,
+			minute: 0,
+			want:   time.Date(2026, 3, 10, 7, 0, 0, 0, location),
+		},
+		{
+			name:   "midnight",
+			day:    time.Date(2026, 3, 10, 0, 0, 0, 0, location),
+			hour:   0,
+			minute: 0,
+			want:   time.Date(2026, 3, 10, 0, 0, 0, 0, location),
+		},
+		{
+			// 02:30 doesn't exist on spring forward, so the first minute after it is used
+			name:   "skipped by spring forward",
+			day:    time.Date(2026, 3, 29, 0, 0, 0, 0, location),
+			hour:   2,
+			minute: 30,
+			want:   time.Date(2026, 3, 29, 3, 0, 0, 0, location),
+		},
+		{
+			// 02:30 comes twice on fall back, only the first one is used
+			name:   "repeated on fall back",
+			day:    time.Date(2026, 10, 25, 0, 0, 0, 0, location),
+			hour:   2,
+			minute: 30,
+			want:   time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fired []time.Time
+			nextDay := tt.day.AddDate(0, 0, 1)
+			for now := tt.day; now.Before(nextDay); now = now.Add(time.Minute) {
+				if IsDailyTime(now, tt.hour, tt.minute) {
+					fired = append(fired, now)
+				}
+			}
+			if len(fired) != 1 || !fired[0].Equal(tt.want) {
+				t.Errorf("IsDailyTime fired at %v, want only at %v", fired, tt.want.In(location))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LanguageEn = "en"
+	LanguageRu = "ru"
+)
+
+var ruWeekdays = []string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+
+var ruShortWeekdays = []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// ruMonths are in genitive case as they are used with day number
+var ruMonths = []string{"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+var ruShortMonths = []string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"}
+
+// InputError keeps message apart from wrong value, so message could be translated.
+// Message is id of message in bundles of conf
+type InputError struct {
+	Message string
+	Value   string
+}
+
+func (e *InputError) Error() string {
+	if e.Value == "" {
+		return e.Message
+	}
+	return e.Message + " '" + e.Value + "'"
+}
+
+// GetLanguage picks supported language for Mattermost locale, English is used by default
+func GetLanguage(locale string) string {
+	if strings.HasPrefix(strings.ToLower(locale), LanguageRu) {
+		return LanguageRu
+	}
+	return LanguageEn
+}
+
+func GetWeekdayName(weekday time.Weekday, language string) string {
+	if language == LanguageRu {
+		return ruWeekdays[weekday]
+	}
+	return weekday.String()
+}
+
+// FormatDate shows day with full month name, e.g. "March 25" or "25 марта"
+func FormatDate(dt time.Time, language string) string {
+	if language == LanguageRu {
+		return strconv.Itoa(dt.Day()) + " " + ruMonths[dt.Month()-1]
+	}
+	return dt.Month().String() + " " + strconv.Itoa(dt.Day())
+}
+
+// FormatShortDate shows day with short month name, e.g. "Mar 25" or "25 мар"
+func FormatShortDate(dt time.Time, language string) string {
+	if language == LanguageRu {
+		return strconv.Itoa(dt.Day()) + " " + ruShortMonths[dt.Month()-1]
+	}
+	return dt.Format("Jan 2")
+}
+
+// FormatShortWeekdayDate shows day with short weekday and month names, e.g. "Mon, Mar 25" or "пн, 25 мар"
+func FormatShortWeekdayDate(dt time.Time, language string) string {
+	if language == LanguageRu {
+		return ruShortWeekdays[dt.Weekday()] + ", " + FormatShortDate(dt, language)
+	}
+	return dt.Format("Mon, Jan 2")
+}
+
+// FormatLocalizedDuration shows duration in hours and minutes, e.g. "1h 30m" or "1 ч 30 мин"
+func FormatLocalizedDuration(d time.Duration, language string) string {
+	if language != LanguageRu {
+		return FormatDuration(d)
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours == 0 {
+		return strconv.Itoa(minutes) + " мин"
+	}
+	if minutes == 0 {
+		return strconv.Itoa(hours) + " ч"
+	}
+	return strconv.Itoa(hours) + " ч " + strconv.Itoa(minutes) + " мин"
+}
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeSearchText lowers text of any script, collapses spaces and treats ё as е,
+// so "Архитектурное  Ревью" and "архитектурное ревью" are equal
+func NormalizeSearchText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r == 'ё' || r == 'Ё' {
+			return 'е'
+		}
+		return unicode.ToLower(r)
+	}, text)
+	return strings.Join(strings.Fields(text), " ")
+}